@@ -21,6 +21,16 @@ config :scribbl_backend, ScribblBackendWeb.Endpoint,
   pubsub_server: ScribblBackend.PubSub,
   live_view: [signing_salt: "5UcRsMeR"]
 
+# The internal listener only starts serving when INTERNAL_PORT is set
+config :scribbl_backend, ScribblBackendWeb.InternalEndpoint,
+  adapter: Bandit.PhoenixAdapter,
+  render_errors: [
+    formats: [json: ScribblBackendWeb.ErrorJSON],
+    layout: false
+  ],
+  pubsub_server: ScribblBackend.PubSub,
+  server: false
+
 # Configures Elixir's Logger
 config :logger, :console,
   format: "$time $metadata[$level] $message\n",
@@ -31,7 +31,101 @@ cors_origins =
       |> Enum.reject(&(&1 == ""))
   end
 
-config :scribbl_backend, :cors_allowed_origins, cors_origins
+# CORS origins and session cookie scope, validated together at startup
+config :scribbl_backend,
+       :origin_policy,
+       ScribblBackendWeb.OriginPolicy.new!(
+         allowed_origins: cors_origins,
+         cookie_domain: System.get_env("COOKIE_DOMAIN"),
+         cookie_same_site: System.get_env("COOKIE_SAME_SITE"),
+         cookie_secure: config_env() == :prod,
+         api_host: System.get_env("PHX_HOST")
+       )
+
+# Proxies whose X-Forwarded-For header is trusted when resolving client IPs
+case System.get_env("TRUSTED_PROXIES") do
+  value when value in [nil, ""] ->
+    :ok
+
+  proxies_string ->
+    config :scribbl_backend,
+           :trusted_proxies,
+           proxies_string |> String.split(",") |> Enum.map(&String.trim/1) |> Enum.reject(&(&1 == ""))
+end
+
+# CIDRs exempt from request limiting (more can be added at runtime via /admin)
+case System.get_env("RATE_LIMIT_EXEMPT_IPS") do
+  value when value in [nil, ""] ->
+    :ok
+
+  exempt_string ->
+    config :scribbl_backend,
+           :rate_limit_exempt_ips,
+           exempt_string |> String.split(",") |> Enum.map(&String.trim/1) |> Enum.reject(&(&1 == ""))
+end
+
+# Maximum number of concurrent HTTP requests per client IP
+max_in_flight_per_ip =
+  case System.get_env("MAX_IN_FLIGHT_PER_IP") do
+    value when value in [nil, ""] -> 10
+    value -> String.to_integer(value)
+  end
+
+config :scribbl_backend, :max_in_flight_per_ip, max_in_flight_per_ip
+
+# Maximum number of /api requests per client IP per minute, for endpoints without their own policy
+rate_limit_per_minute =
+  case System.get_env("RATE_LIMIT_PER_MINUTE") do
+    value when value in [nil, ""] -> 60
+    value -> String.to_integer(value)
+  end
+
+config :scribbl_backend, :rate_limit_per_minute, rate_limit_per_minute
+
+# Per-endpoint rate limit overrides (JSON object, see ScribblBackendWeb.RateLimitPolicies)
+case System.get_env("RATE_LIMIT_POLICIES") do
+  value when value in [nil, ""] -> :ok
+  json -> config :scribbl_backend, :rate_limit_policies, ScribblBackendWeb.RateLimitPolicies.parse_overrides!(json)
+end
+
+# Also serve the internal admin API on this Unix domain socket path (for co-located sidecars)
+case System.get_env("UNIX_SOCKET_PATH") do
+  value when value in [nil, ""] -> :ok
+  path -> config :scribbl_backend, :unix_socket_path, path
+end
+
+# Endpoints scheduled for removal (JSON array, see ScribblBackendWeb.Deprecations)
+case System.get_env("API_DEPRECATIONS") do
+  value when value in [nil, ""] -> :ok
+  json -> config :scribbl_backend, :deprecations, ScribblBackendWeb.Deprecations.parse!(json)
+end
+
+# Start in read-only mode (can also be toggled at runtime via /admin/read-only)
+config :scribbl_backend, :read_only_mode, System.get_env("READ_ONLY_MODE") in ~w(true 1)
+
+# Dedicated internal listener for the admin API, optionally with its own TLS certificate
+case System.get_env("INTERNAL_PORT") do
+  value when value in [nil, ""] ->
+    :ok
+
+  port ->
+    listener = [ip: {0, 0, 0, 0, 0, 0, 0, 0}, port: String.to_integer(port)]
+
+    transport =
+      case {System.get_env("INTERNAL_TLS_CERTFILE"), System.get_env("INTERNAL_TLS_KEYFILE")} do
+        {certfile, keyfile} when certfile in [nil, ""] or keyfile in [nil, ""] ->
+          [http: listener]
+
+        {certfile, keyfile} ->
+          [https: listener ++ [cipher_suite: :strong, certfile: certfile, keyfile: keyfile]]
+      end
+
+    config :scribbl_backend, ScribblBackendWeb.InternalEndpoint, [server: true] ++ transport
+    config :scribbl_backend, :internal_listener, true
+end
+
+# Bearer token for the internal /admin API. The admin API is disabled when unset.
+config :scribbl_backend, :admin_api_token, ScribblBackend.ConfigCrypto.get_env("ADMIN_API_TOKEN")
 
 if config_env() == :prod do
   # The secret key base is used to sign/encrypt cookies and other secrets.
@@ -39,8 +133,9 @@ if config_env() == :prod do
   # want to use a different value for prod and you most likely don't want
   # to check this value into version control, so we use an environment
   # variable instead.
+  # Secrets may be provided encrypted (enc:v1:...), see ScribblBackend.ConfigCrypto
   secret_key_base =
-    System.get_env("SECRET_KEY_BASE") ||
+    ScribblBackend.ConfigCrypto.get_env("SECRET_KEY_BASE") ||
       raise """
       environment variable SECRET_KEY_BASE is missing.
       You can generate one by calling: mix phx.gen.secret
@@ -63,6 +158,8 @@ if config_env() == :prod do
     ],
     secret_key_base: secret_key_base
 
+  config :scribbl_backend, ScribblBackendWeb.InternalEndpoint, secret_key_base: secret_key_base
+
   # ## SSL Support
   #
   # To get SSL working, you will need to add the `https` key
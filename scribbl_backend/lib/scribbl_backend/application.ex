@@ -7,6 +7,8 @@ defmodule ScribblBackend.Application do
 
   @impl true
   def start(_type, _args) do
+    started_at = System.monotonic_time(:millisecond)
+
     children = [
       ScribblBackendWeb.Telemetry,
       {DNSCluster, query: Application.get_env(:scribbl_backend, :dns_cluster_query) || :ignore},
@@ -15,7 +17,7 @@ defmodule ScribblBackend.Application do
        adapter: Phoenix.PubSub.Redis,
        host: System.get_env("REDIS_HOST") || "localhost",
        port: (System.get_env("REDIS_PORT") || "6379") |> String.to_integer(),
-       password: System.get_env("REDIS_PASSWORD"),
+       password: ScribblBackend.ConfigCrypto.get_env("REDIS_PASSWORD"),
        database: (System.get_env("REDIS_DB") || "0") |> String.to_integer(),
        node_name: System.get_env("NODE_NAME") || "default_node"},
       # Redis connection pool
@@ -23,18 +25,67 @@ defmodule ScribblBackend.Application do
        pool_size: String.to_integer(System.get_env("REDIS_POOL_SIZE") || "5"),
        host: System.get_env("REDIS_HOST") || "localhost",
        port: (System.get_env("REDIS_PORT") || "6379") |> String.to_integer(),
-       password: System.get_env("REDIS_PASSWORD"),
+       password: ScribblBackend.ConfigCrypto.get_env("REDIS_PASSWORD"),
        database: (System.get_env("REDIS_DB") || "0") |> String.to_integer()},
       ScribblBackend.TimeoutWatcher,
+      ScribblBackend.LogLevel,
+      ScribblBackend.ReadOnlyMode,
+      ScribblBackendWeb.RateLimitExemptions,
+      ScribblBackend.HealthMonitor,
       ScribblBackendWeb.Presence,
+      ScribblBackendWeb.ConcurrencyLimiter,
+      ScribblBackendWeb.RejectionMetrics,
+      # Must start before the endpoints, see ShutdownReport
+      ScribblBackend.ShutdownReport,
+      ScribblBackendWeb.InternalEndpoint,
       # Start to serve requests, typically the last entry
       ScribblBackendWeb.Endpoint
-    ]
+    ] ++ unix_socket_listener()
 
     # See https://hexdocs.pm/elixir/Supervisor.html
     # for other strategies and supported options
     opts = [strategy: :one_for_one, name: ScribblBackend.Supervisor]
-    Supervisor.start_link(children, opts)
+
+    with {:ok, pid} <- Supervisor.start_link(children, opts) do
+      startup_ms = System.monotonic_time(:millisecond) - started_at
+      # Report in the background so a slow Redis handshake doesn't delay boot
+      Task.start(fn -> ScribblBackend.Lifecycle.started(startup_ms) end)
+      {:ok, pid}
+    end
+  end
+
+  @impl true
+  def prep_stop(state) do
+    ScribblBackend.Lifecycle.stopping()
+    ScribblBackend.ShutdownReport.begin()
+    state
+  end
+
+  @impl true
+  def stop(_state) do
+    ScribblBackend.Lifecycle.stopped()
+    # Stops the VM with a non-zero exit code if the application crashed
+    ScribblBackend.ShutdownReport.report()
+  end
+
+  # Optionally also serve the internal endpoint on a Unix domain socket, so
+  # sidecars on the same host can reach the admin API without a TCP port
+  defp unix_socket_listener do
+    case Application.get_env(:scribbl_backend, :unix_socket_path) do
+      nil ->
+        []
+
+      path ->
+        # A socket file left behind by a previous run would make the bind fail
+        File.rm(path)
+
+        [
+          Supervisor.child_spec(
+            {Bandit, plug: ScribblBackendWeb.InternalEndpoint, scheme: :http, ip: {:local, path}, port: 0},
+            id: :unix_socket_listener
+          )
+        ]
+    end
   end
 
   # Tell Phoenix to update the endpoint configuration
@@ -42,6 +93,7 @@ defmodule ScribblBackend.Application do
   @impl true
   def config_change(changed, _new, removed) do
     ScribblBackendWeb.Endpoint.config_change(changed, removed)
+    ScribblBackendWeb.InternalEndpoint.config_change(changed, removed)
     :ok
   end
 end
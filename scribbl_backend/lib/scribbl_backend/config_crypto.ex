@@ -0,0 +1,136 @@
+defmodule ScribblBackend.ConfigCrypto do
+  @moduledoc """
+  Decrypts configuration values that are provided encrypted, so secrets in
+  env files and deployment manifests don't have to be stored in plaintext.
+
+  Encrypted values have the form `enc:v1:<base64>`, where the decoded payload
+  is a 12-byte IV, a 16-byte tag and the AES-256-GCM ciphertext. The 32-byte
+  master key is read base64-encoded from `CONFIG_MASTER_KEY`, or from the file
+  named by `CONFIG_MASTER_KEY_FILE` (e.g. a secret mounted from a KMS).
+
+  Values without the `enc:` prefix are returned unchanged, so plaintext
+  configuration keeps working.
+
+  To produce an encrypted value for a deployment:
+
+      bin/scribbl_backend eval 'IO.puts ScribblBackend.ConfigCrypto.encrypt("secret", System.fetch_env!("CONFIG_MASTER_KEY"))'
+  """
+
+  @prefix "enc:v1:"
+  @aad "scribbl-config:v1"
+  @iv_size 12
+  @tag_size 16
+  @key_size 32
+
+  @doc """
+  Read an environment variable, decrypting it if it is encrypted.
+  Raises if the value is encrypted but cannot be decrypted, since a
+  half-configured secret should stop the boot rather than fail later.
+
+  ## Parameters
+    - `name`: The name of the environment variable.
+    - `default`: The value to return if the variable is unset or empty.
+
+  ## Examples
+      iex> ScribblBackend.ConfigCrypto.get_env("REDIS_PASSWORD")
+      "my_password"
+  """
+  def get_env(name, default \\ nil) do
+    case System.get_env(name) do
+      value when value in [nil, ""] ->
+        default
+
+      value ->
+        case decrypt(value) do
+          {:ok, plaintext} -> plaintext
+          {:error, reason} -> raise "could not decrypt environment variable #{name}: #{reason}"
+        end
+    end
+  end
+
+  @doc """
+  Decrypt a configuration value. Plaintext values are passed through.
+
+  ## Parameters
+    - `value`: The configuration value, encrypted or not.
+
+  ## Returns
+    `{:ok, plaintext}` or `{:error, reason}`.
+  """
+  def decrypt(@prefix <> payload) do
+    with {:ok, key} <- master_key(),
+         {:ok, decoded} <- decode_payload(payload),
+         <<iv::binary-size(@iv_size), tag::binary-size(@tag_size), ciphertext::binary>> <- decoded,
+         plaintext when is_binary(plaintext) <-
+           :crypto.crypto_one_time_aead(:aes_256_gcm, key, iv, ciphertext, @aad, tag, false) do
+      {:ok, plaintext}
+    else
+      {:error, reason} -> {:error, reason}
+      _ -> {:error, "invalid ciphertext or wrong master key"}
+    end
+  end
+
+  def decrypt("enc:" <> _rest), do: {:error, "unsupported encryption version"}
+
+  def decrypt(value) when is_binary(value), do: {:ok, value}
+
+  @doc """
+  Encrypt a value with the given base64-encoded master key.
+
+  ## Parameters
+    - `plaintext`: The value to encrypt.
+    - `encoded_key`: The base64-encoded 32-byte master key.
+
+  ## Returns
+    The encrypted value, prefixed with `enc:v1:`.
+  """
+  def encrypt(plaintext, encoded_key) when is_binary(plaintext) do
+    <<_::binary-size(@key_size)>> = key = Base.decode64!(encoded_key)
+    iv = :crypto.strong_rand_bytes(@iv_size)
+
+    {ciphertext, tag} =
+      :crypto.crypto_one_time_aead(:aes_256_gcm, key, iv, plaintext, @aad, true)
+
+    @prefix <> Base.encode64(iv <> tag <> ciphertext)
+  end
+
+  @doc """
+  Generate a new random master key, base64-encoded.
+  """
+  def generate_key, do: @key_size |> :crypto.strong_rand_bytes() |> Base.encode64()
+
+  defp decode_payload(payload) do
+    case Base.decode64(payload) do
+      {:ok, decoded} -> {:ok, decoded}
+      :error -> {:error, "payload is not valid base64"}
+    end
+  end
+
+  defp master_key do
+    with {:ok, encoded} <- read_master_key(),
+         {:ok, <<_::binary-size(@key_size)>> = key} <- Base.decode64(encoded) do
+      {:ok, key}
+    else
+      {:error, reason} -> {:error, reason}
+      _ -> {:error, "master key must be #{@key_size} bytes, base64-encoded"}
+    end
+  end
+
+  # The key file takes precedence so the key never has to live in the environment.
+  # Empty values count as unset, as sample.env ships both variables empty.
+  defp read_master_key do
+    case {System.get_env("CONFIG_MASTER_KEY_FILE"), System.get_env("CONFIG_MASTER_KEY")} do
+      {path, key} when path in [nil, ""] and key in [nil, ""] ->
+        {:error, "CONFIG_MASTER_KEY or CONFIG_MASTER_KEY_FILE must be set"}
+
+      {path, key} when path in [nil, ""] ->
+        {:ok, String.trim(key)}
+
+      {path, _key} ->
+        case File.read(path) do
+          {:ok, contents} -> {:ok, String.trim(contents)}
+          {:error, reason} -> {:error, "could not read #{path}: #{:file.format_error(reason)}"}
+        end
+    end
+  end
+end
@@ -0,0 +1,150 @@
+defmodule ScribblBackend.HealthMonitor do
+  @moduledoc """
+  A GenServer that periodically checks the health of the backend's
+  dependencies and caches the result, so health reporting never queries
+  Redis on the request path.
+
+  Each dependency is `:healthy`, `:degraded` (responding, but slowly) or
+  `:unhealthy` (not responding).
+
+  Every status change is logged with its cause, emitted as a
+  `[:scribbl_backend, :health, :transition]` telemetry event and kept in a
+  bounded history, so postmortems can reconstruct when and why readiness
+  flapped.
+  """
+
+  use GenServer
+  require Logger
+
+  alias ScribblBackend.RedisHelper
+
+  @check_interval_ms 5_000
+  @check_timeout_ms 1_000
+  @slow_threshold_ms 250
+  @max_history 100
+
+  ## Client API
+
+  def start_link(_opts) do
+    GenServer.start_link(__MODULE__, %{transitions: [], counts: %{}}, name: __MODULE__)
+  end
+
+  @doc """
+  Get the cached health of every dependency.
+
+  ## Returns
+    A map of dependency to `%{status: status, since: datetime}`, e.g.
+    `%{redis: %{status: :healthy, since: ~U[2025-01-01 00:00:00Z]}}`.
+  """
+  def all do
+    :persistent_term.get(__MODULE__, %{})
+  end
+
+  @doc """
+  Get the status changes recorded since this node started.
+
+  ## Returns
+    A map with:
+    - `:transitions`: The most recent changes, newest first, each a map with
+      the `dependency`, `from` and `to` statuses, `cause` and `at` timestamp.
+      The first check of a dependency is recorded as a change from `:unknown`.
+    - `:counts`: The number of changes per `{dependency, from, to}`.
+  """
+  def history do
+    GenServer.call(__MODULE__, :history)
+  end
+
+  ## Server Callbacks
+
+  @impl true
+  def init(state) do
+    {:ok, state, {:continue, :check}}
+  end
+
+  @impl true
+  def handle_continue(:check, state) do
+    {:noreply, check(state)}
+  end
+
+  @impl true
+  def handle_call(:history, _from, state) do
+    {:reply, state, state}
+  end
+
+  @impl true
+  def handle_info(:check, state) do
+    {:noreply, check(state)}
+  end
+
+  def handle_info(_msg, state) do
+    {:noreply, state}
+  end
+
+  defp check(state) do
+    {status, cause} = redis_status()
+    state = update(state, :redis, status, cause)
+    Process.send_after(self(), :check, @check_interval_ms)
+    state
+  end
+
+  # The cached health (and its `since`) only changes on a status transition
+  defp update(state, dependency, status, cause) do
+    health = all()
+
+    case health do
+      %{^dependency => %{status: ^status}} ->
+        state
+
+      _ ->
+        from = get_in(health, [dependency, :status]) || :unknown
+        now = DateTime.utc_now()
+
+        :persistent_term.put(
+          __MODULE__,
+          Map.put(health, dependency, %{status: status, since: now})
+        )
+
+        record_transition(state, %{dependency: dependency, from: from, to: status, cause: cause, at: now})
+    end
+  end
+
+  defp record_transition(state, transition) do
+    %{dependency: dependency, from: from, to: to, cause: cause, at: at} = transition
+
+    Logger.log(
+      if(to == :healthy, do: :info, else: :warning),
+      "[HealthMonitor] #{dependency} #{from} -> #{to} at #{DateTime.to_iso8601(at)}: #{cause}"
+    )
+
+    :telemetry.execute(
+      [:scribbl_backend, :health, :transition],
+      %{count: 1},
+      %{dependency: dependency, from: from, to: to, cause: cause}
+    )
+
+    %{
+      state
+      | transitions: Enum.take([transition | state.transitions], @max_history),
+        counts: Map.update(state.counts, {dependency, from, to}, 1, &(&1 + 1))
+    }
+  end
+
+  defp redis_status do
+    started_at = System.monotonic_time(:millisecond)
+
+    case Redix.command(RedisHelper.redix_conn(), ["PING"], timeout: @check_timeout_ms) do
+      {:ok, "PONG"} ->
+        elapsed_ms = System.monotonic_time(:millisecond) - started_at
+
+        if elapsed_ms > @slow_threshold_ms,
+          do: {:degraded, "PING took #{elapsed_ms}ms"},
+          else: {:healthy, "PING took #{elapsed_ms}ms"}
+
+      {:ok, reply} ->
+        {:unhealthy, "unexpected PING reply #{inspect(reply)}"}
+
+      {:error, error} ->
+        {:unhealthy, Exception.message(error)}
+    end
+  end
+end
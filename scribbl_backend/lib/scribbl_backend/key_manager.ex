@@ -244,6 +244,26 @@ defmodule ScribblBackend.KeyManager do
   """
   def public_rooms(), do: "public_rooms"
 
+  @doc """
+  Get the key for the set of IPs and CIDRs exempt from rate limiting.
+
+  ## Returns
+    The Redis key for the rate limit exemptions set.
+  """
+  def rate_limit_exemptions(), do: "rate_limit:exemptions"
+
+  @doc """
+  Get the key for a client's request counter in a rate limit bucket.
+
+  ## Parameters
+    - `bucket`: The name of the rate limit bucket, e.g. `"api"`.
+    - `client`: The client identifier, e.g. a formatted IP address.
+
+  ## Returns
+    The Redis key for the request counter.
+  """
+  def rate_limit(bucket, client), do: "rate_limit:#{bucket}:#{client}"
+
   @doc """
   Get the key for storing word selection words separately from the timer.
   This allows words to persist after the timer key expires.
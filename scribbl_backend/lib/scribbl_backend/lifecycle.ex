@@ -0,0 +1,116 @@
+defmodule ScribblBackend.Lifecycle do
+  @moduledoc """
+  Emits machine-readable lifecycle events when the application starts and
+  stops, so deploy tooling can verify rollouts programmatically.
+
+  Each event is logged as a single JSON line prefixed with `lifecycle:` and
+  emitted as a `[:scribbl_backend, :lifecycle, event]` telemetry event. The
+  lines are logged regardless of the runtime log level, see
+  `ScribblBackend.LogLevel`, so raising it doesn't hide rollouts.
+
+  ## Events
+    - `app.started`: version, node, config hash, startup duration and Redis status.
+    - `app.stopping`: emitted before the supervision tree shuts down.
+    - `app.stopped`: emitted after the supervision tree has shut down.
+    - `app.shutdown_report`: shutdown kind, exit code and drain summary, see `ScribblBackend.ShutdownReport`.
+  """
+
+  require Logger
+
+  alias ScribblBackend.RedisHelper
+
+  @redis_ping_attempts 5
+  @redis_ping_interval_ms 200
+
+  @doc """
+  Emit the `app.started` event.
+
+  ## Parameters
+    - `startup_ms`: How long the supervision tree took to start.
+  """
+  def started(startup_ms) do
+    emit(:started, %{
+      startup_ms: startup_ms,
+      config_hash: config_hash(),
+      dependencies: %{redis: redis_status(@redis_ping_attempts)}
+    })
+  end
+
+  @doc """
+  Emit the `app.stopping` event.
+  """
+  def stopping, do: emit(:stopping, %{uptime_ms: uptime_ms()})
+
+  @doc """
+  Emit the `app.stopped` event.
+  """
+  def stopped, do: emit(:stopped, %{uptime_ms: uptime_ms()})
+
+  @doc """
+  Emit the `app.shutdown_report` event.
+
+  ## Parameters
+    - `report`: The shutdown summary fields.
+  """
+  def shutdown_report(report), do: emit(:shutdown_report, report)
+
+  defp emit(event, fields) do
+    metadata =
+      Map.merge(
+        %{
+          event: "app.#{event}",
+          version: version(),
+          node: System.get_env("NODE_NAME") || Atom.to_string(Node.self()),
+          timestamp: DateTime.utc_now() |> DateTime.to_iso8601()
+        },
+        fields
+      )
+
+    :telemetry.execute(
+      [:scribbl_backend, :lifecycle, event],
+      %{system_time: System.system_time()},
+      metadata
+    )
+
+    # A module level overrides the global one, which may be raised at runtime
+    Logger.put_module_level(__MODULE__, :all)
+    Logger.notice("lifecycle: " <> Jason.encode!(metadata))
+  end
+
+  defp version do
+    :scribbl_backend |> Application.spec(:vsn) |> to_string()
+  end
+
+  # VM uptime, which for a release is the container's uptime
+  defp uptime_ms do
+    {uptime, _since_last_call} = :erlang.statistics(:wall_clock)
+    uptime
+  end
+
+  # A short fingerprint of the application config, so rollouts can be checked
+  # for config drift between nodes without exposing any values
+  defp config_hash do
+    :scribbl_backend
+    |> Application.get_all_env()
+    |> Enum.sort()
+    |> :erlang.term_to_binary([:deterministic])
+    |> then(&:crypto.hash(:sha256, &1))
+    |> Base.encode16(case: :lower)
+    |> binary_part(0, 12)
+  end
+
+  # Redix connects asynchronously, so give the pool a moment right after boot
+  defp redis_status(attempts) do
+    case Redix.command(RedisHelper.redix_conn(), ["PING"]) do
+      {:ok, "PONG"} ->
+        "ok"
+
+      _ when attempts > 1 ->
+        Process.sleep(@redis_ping_interval_ms)
+        redis_status(attempts - 1)
+
+      _ ->
+        "unavailable"
+    end
+  end
+end
@@ -0,0 +1,100 @@
+defmodule ScribblBackend.LogLevel do
+  @moduledoc """
+  A GenServer that changes the Logger level at runtime and reverts it
+  automatically, so verbose logging can be enabled during incident
+  debugging without a restart.
+
+  Changes are broadcast over PubSub so every node in the cluster applies
+  them, regardless of which node served the admin request.
+  """
+
+  use GenServer
+  require Logger
+
+  @topic "admin:log_level"
+  @default_duration_ms :timer.minutes(10)
+  @max_duration_ms :timer.hours(1)
+  @levels ~w(emergency alert critical error warning notice info debug)
+
+  ## Client API
+
+  def start_link(_opts) do
+    GenServer.start_link(__MODULE__, %{}, name: __MODULE__)
+  end
+
+  @doc """
+  Set the log level on all nodes for a limited time.
+
+  ## Parameters
+    - `level`: The level name, e.g. `"debug"`.
+    - `duration_ms`: How long to keep the level before reverting. Default: 10 minutes, max: 1 hour.
+
+  ## Returns
+    `{:ok, %{level: level, revert_at: datetime}}` or `{:error, reason}`.
+  """
+  def set_level(level, duration_ms \\ @default_duration_ms)
+
+  def set_level(level, duration_ms)
+      when level in @levels and is_integer(duration_ms) and duration_ms > 0 do
+    duration_ms = min(duration_ms, @max_duration_ms)
+
+    Phoenix.PubSub.broadcast(
+      ScribblBackend.PubSub,
+      @topic,
+      {:set_log_level, String.to_existing_atom(level), duration_ms}
+    )
+
+    {:ok,
+     %{
+       level: level,
+       revert_at: DateTime.utc_now() |> DateTime.add(duration_ms, :millisecond)
+     }}
+  end
+
+  def set_level(level, _duration_ms) when level in @levels,
+    do: {:error, "Duration must be a positive number of seconds"}
+
+  def set_level(_level, _duration_ms),
+    do: {:error, "Level must be one of: #{Enum.join(@levels, ", ")}"}
+
+  @doc """
+  Get the current log level of this node and the level it reverts to.
+  """
+  def current do
+    GenServer.call(__MODULE__, :current)
+  end
+
+  ## Server Callbacks
+
+  @impl true
+  def init(_state) do
+    Phoenix.PubSub.subscribe(ScribblBackend.PubSub, @topic)
+    {:ok, %{base_level: Logger.level(), revert_timer: nil}}
+  end
+
+  @impl true
+  def handle_call(:current, _from, state) do
+    {:reply, %{level: Logger.level(), base_level: state.base_level}, state}
+  end
+
+  @impl true
+  def handle_info({:set_log_level, level, duration_ms}, state) do
+    # A newer change replaces the pending revert of an older one
+    if state.revert_timer, do: Process.cancel_timer(state.revert_timer)
+
+    Logger.configure(level: level)
+    Logger.warning("[LogLevel] Log level set to #{level} for #{div(duration_ms, 1000)}s")
+
+    {:noreply, %{state | revert_timer: Process.send_after(self(), :revert, duration_ms)}}
+  end
+
+  def handle_info(:revert, state) do
+    Logger.configure(level: state.base_level)
+    Logger.warning("[LogLevel] Log level reverted to #{state.base_level}")
+    {:noreply, %{state | revert_timer: nil}}
+  end
+
+  def handle_info(_msg, state) do
+    {:noreply, state}
+  end
+end
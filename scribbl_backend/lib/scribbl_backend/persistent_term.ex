@@ -0,0 +1,27 @@
+defmodule ScribblBackend.PersistentTerm do
+  @moduledoc """
+  Helpers for the node-wide settings cached in `:persistent_term`, which
+  are read on hot paths but rarely change.
+  """
+
+  @doc """
+  Store a value unless the key already holds an equal one. Updating a
+  persistent term triggers a global GC, so unchanged values (e.g. from a
+  periodic reload) must not be written again.
+
+  ## Parameters
+    - `key`: The persistent term key.
+    - `value`: The new value.
+
+  ## Returns
+    `true` if the value was stored, `false` if it was unchanged.
+  """
+  def put_if_changed(key, value) do
+    if :persistent_term.get(key, {__MODULE__, :unset}) == value do
+      false
+    else
+      :persistent_term.put(key, value)
+      true
+    end
+  end
+end
@@ -0,0 +1,71 @@
+defmodule ScribblBackend.PlayerName do
+  @moduledoc """
+  Sanitizes and validates player names sent when joining a room.
+
+  Names may use any script (e.g. Devanagari or emoji), so lengths are counted
+  in grapheme clusters rather than bytes or codepoints, and the zero-width
+  joiners that Indic scripts and emoji sequences rely on are kept.
+  """
+
+  @max_graphemes 20
+  # Guards against "zalgo" names stacking endless combining marks on one
+  # character. Long emoji ZWJ sequences (e.g. families with skin tones) fit.
+  @max_codepoints_per_grapheme 16
+
+  # Bidi controls, which can visually reorder surrounding chat text, and
+  # invisible characters that make names look identical to each other
+  @invisible ~r/[\x{00AD}\x{061C}\x{200B}\x{200E}\x{200F}\x{202A}-\x{202E}\x{2060}\x{2066}-\x{2069}\x{FEFF}]/u
+
+  # Letters, marks (incl. the emoji variation selector), numbers, punctuation,
+  # symbols (incl. emoji), spaces, ZWNJ/ZWJ and the tag characters of
+  # subdivision flags such as Scotland's
+  @allowed ~r/^[\p{L}\p{M}\p{N}\p{P}\p{S}\x{20}\x{200C}\x{200D}\x{E0020}-\x{E007F}]+$/u
+
+  @doc """
+  Normalize a valid UTF-8 player name: NFC-normalizes it, strips bidi and
+  zero-width characters, and collapses whitespace.
+
+  ## Examples
+      iex> ScribblBackend.PlayerName.sanitize("  Ana\\u200B \\n Maria ")
+      "Ana Maria"
+  """
+  def sanitize(name) when is_binary(name) do
+    name
+    |> :unicode.characters_to_nfc_binary()
+    |> String.replace(@invisible, "")
+    |> String.replace(~r/[\s\p{Cc}]+/u, " ")
+    |> String.trim()
+  end
+
+  @doc """
+  Sanitize and validate a player name.
+
+  ## Returns
+    `{:ok, sanitized_name}` or `{:error, reason}`.
+  """
+  def validate(name) when is_binary(name) do
+    if String.valid?(name), do: name |> sanitize() |> check(), else: {:error, "Name is not valid UTF-8"}
+  end
+
+  def validate(_name), do: {:error, "Name must be a string"}
+
+  defp check(""), do: {:error, "Name can't be empty"}
+
+  defp check(name) do
+    graphemes = String.graphemes(name)
+
+    cond do
+      length(graphemes) > @max_graphemes ->
+        {:error, "Name can be at most #{@max_graphemes} characters"}
+
+      not Regex.match?(@allowed, name) ->
+        {:error, "Name contains unsupported characters"}
+
+      Enum.any?(graphemes, &(length(String.codepoints(&1)) > @max_codepoints_per_grapheme)) ->
+        {:error, "Name contains unsupported characters"}
+
+      true ->
+        {:ok, name}
+    end
+  end
+end
@@ -0,0 +1,66 @@
+defmodule ScribblBackend.ReadOnlyMode do
+  @moduledoc """
+  A GenServer that holds the read-only mode flag, used during Redis
+  maintenance windows. While enabled, mutating API requests are rejected
+  with 503 `READ_ONLY`, and room joins and game actions sent over the room
+  channel (drawing, guesses, settings, turns, voice, kick votes) are refused
+  with the `READ_ONLY` code, while reads keep working. Turn timers that are
+  already running still fire.
+
+  The initial state comes from `READ_ONLY_MODE`. Toggles through the admin
+  API are broadcast over PubSub so every running node applies them; a node
+  that starts later uses `READ_ONLY_MODE` again.
+  """
+
+  use GenServer
+  require Logger
+
+  alias ScribblBackend.PersistentTerm
+
+  @topic "admin:read_only"
+
+  ## Client API
+
+  def start_link(_opts) do
+    GenServer.start_link(__MODULE__, %{}, name: __MODULE__)
+  end
+
+  @doc """
+  Check whether read-only mode is enabled on this node.
+  """
+  def enabled? do
+    :persistent_term.get(__MODULE__, false)
+  end
+
+  @doc """
+  Enable or disable read-only mode on all nodes.
+
+  ## Parameters
+    - `enabled`: `true` to reject writes, `false` to accept them again.
+  """
+  def set(enabled) when is_boolean(enabled) do
+    Phoenix.PubSub.broadcast(ScribblBackend.PubSub, @topic, {:set_read_only, enabled})
+  end
+
+  ## Server Callbacks
+
+  @impl true
+  def init(state) do
+    Phoenix.PubSub.subscribe(ScribblBackend.PubSub, @topic)
+    :persistent_term.put(__MODULE__, Application.get_env(:scribbl_backend, :read_only_mode, false))
+    {:ok, state}
+  end
+
+  @impl true
+  def handle_info({:set_read_only, enabled}, state) do
+    if PersistentTerm.put_if_changed(__MODULE__, enabled) do
+      Logger.warning("[ReadOnlyMode] Read-only mode #{if enabled, do: "enabled", else: "disabled"}")
+    end
+
+    {:noreply, state}
+  end
+
+  def handle_info(_msg, state) do
+    {:noreply, state}
+  end
+end
@@ -0,0 +1,174 @@
+defmodule ScribblBackend.ShutdownReport do
+  @moduledoc """
+  Summarizes each shutdown with a kind and exit code that deploy
+  automation can act on.
+
+  The report is emitted as an `app.shutdown_report` lifecycle event with the
+  shutdown kind, its duration, the HTTP requests in flight when shutdown began,
+  the requests that were dropped instead of drained, and the websocket
+  connections (game channels) that the shutdown closed.
+
+  Requests and websockets are counted from Bandit's telemetry events, so
+  every listener is covered (public, internal and Unix socket) regardless of
+  rate limit exemptions.
+
+  ## Exit codes
+    - `0` (`clean`): shutdown was requested and every in-flight request drained.
+    - `1` (`crashed`): the application terminated without a shutdown request.
+    - `2` (`forced`): shutdown was requested but requests were dropped before completing.
+    - `3` (`dependency_failure`): the application terminated without a shutdown
+      request while Redis was unreachable.
+
+  A shutdown counts as requested when OTP ran `prep_stop/1`, which it does
+  for `System.stop/1` (e.g. SIGTERM) and `Application.stop/1`, but not when
+  the supervision tree crashed. Requested shutdowns never change how the VM
+  exits, so stopping the application from a console or a test doesn't kill
+  the node; `forced` is reported in the event only. After a crash the VM is
+  stopped with the exit code through `:init.stop/1`, so the remaining
+  applications still shut down in order.
+
+  This process starts before the endpoints in the supervision tree. Children
+  stop in reverse order, so it terminates right after the endpoints have
+  drained and can count the requests that never completed.
+  """
+
+  use GenServer
+
+  alias ScribblBackend.Lifecycle
+
+  @exit_codes %{clean: 0, crashed: 1, forced: 2, dependency_failure: 3}
+  @redis_connect_timeout_ms 1000
+
+  # Indexes into the :counters array
+  @http_requests 1
+  @websockets 2
+
+  @events [
+    [:bandit, :request, :start],
+    [:bandit, :request, :stop],
+    [:bandit, :request, :exception],
+    [:bandit, :websocket, :start],
+    [:bandit, :websocket, :stop]
+  ]
+
+  ## Client API
+
+  def start_link(_opts) do
+    GenServer.start_link(__MODULE__, %{}, name: __MODULE__)
+  end
+
+  @doc """
+  Mark the start of a requested shutdown. Called from `Application.prep_stop/1`.
+  """
+  def begin do
+    :persistent_term.put({__MODULE__, :began_at}, System.monotonic_time(:millisecond))
+    :persistent_term.put({__MODULE__, :in_flight}, count(@http_requests))
+    :persistent_term.put({__MODULE__, :websockets}, count(@websockets))
+  end
+
+  @doc """
+  Emit the shutdown report, and stop the VM with the exit code if the
+  application crashed. Called from `Application.stop/1`.
+  """
+  def report do
+    began_at = :persistent_term.get({__MODULE__, :began_at}, nil)
+    dropped = :persistent_term.get({__MODULE__, :dropped}, 0)
+    kind = kind(began_at != nil, dropped, &redis_reachable?/0)
+    exit_code = Map.fetch!(@exit_codes, kind)
+    now = System.monotonic_time(:millisecond)
+
+    Lifecycle.shutdown_report(%{
+      kind: kind,
+      exit_code: exit_code,
+      duration_ms: now - (began_at || now),
+      in_flight_requests: :persistent_term.get({__MODULE__, :in_flight}, 0),
+      dropped_requests: dropped,
+      # Game sockets don't drain, every connection open at shutdown is closed
+      closed_websockets: :persistent_term.get({__MODULE__, :websockets}, 0)
+    })
+
+    # The application may be started again in the same VM
+    Enum.each([:began_at, :dropped, :in_flight, :websockets], &:persistent_term.erase({__MODULE__, &1}))
+
+    if kind in [:crashed, :dependency_failure] do
+      :init.stop(exit_code)
+    end
+
+    :ok
+  end
+
+  @doc """
+  Classify a shutdown.
+
+  ## Parameters
+    - `requested?`: Whether the shutdown was requested (`prep_stop/1` ran).
+    - `dropped`: The number of requests that never completed.
+    - `redis_reachable?`: A function checking Redis, only called for crashes.
+
+  ## Returns
+    `:clean`, `:forced`, `:crashed` or `:dependency_failure`.
+  """
+  def kind(true, 0, _redis_reachable?), do: :clean
+  def kind(true, _dropped, _redis_reachable?), do: :forced
+
+  def kind(false, _dropped, redis_reachable?) do
+    if redis_reachable?.(), do: :crashed, else: :dependency_failure
+  end
+
+  ## Server Callbacks
+
+  @impl true
+  def init(state) do
+    # Trap exits so terminate/2 runs when the supervisor shuts us down
+    Process.flag(:trap_exit, true)
+
+    :persistent_term.put({__MODULE__, :counters}, :counters.new(2, [:write_concurrency]))
+    :telemetry.detach(__MODULE__)
+    :telemetry.attach_many(__MODULE__, @events, &__MODULE__.handle_event/4, nil)
+
+    {:ok, state}
+  end
+
+  @impl true
+  def terminate(_reason, _state) do
+    # The endpoints have stopped by now, so any request still counted never completed
+    :persistent_term.put({__MODULE__, :dropped}, count(@http_requests))
+    :telemetry.detach(__MODULE__)
+  end
+
+  @doc false
+  # Runs in the connection process. Every request start is paired with
+  # exactly one stop or exception event.
+  def handle_event([:bandit, :request, :start], _measurements, _metadata, _config), do: add(@http_requests, 1)
+  def handle_event([:bandit, :request, _end], _measurements, _metadata, _config), do: add(@http_requests, -1)
+  def handle_event([:bandit, :websocket, :start], _measurements, _metadata, _config), do: add(@websockets, 1)
+  def handle_event([:bandit, :websocket, :stop], _measurements, _metadata, _config), do: add(@websockets, -1)
+
+  defp add(index, delta) do
+    {__MODULE__, :counters} |> :persistent_term.get() |> :counters.add(index, delta)
+  end
+
+  # Requests that started before a restart of this process end after it,
+  # so don't report a negative count
+  defp count(index) do
+    case :persistent_term.get({__MODULE__, :counters}, nil) do
+      nil -> 0
+      counters -> max(:counters.get(counters, index), 0)
+    end
+  end
+
+  # The Redis pool is already stopped at this point, so probe the port directly
+  defp redis_reachable? do
+    host = System.get_env("REDIS_HOST") || "localhost"
+    port = String.to_integer(System.get_env("REDIS_PORT") || "6379")
+
+    case :gen_tcp.connect(String.to_charlist(host), port, [], @redis_connect_timeout_ms) do
+      {:ok, socket} ->
+        :gen_tcp.close(socket)
+        true
+
+      {:error, _reason} ->
+        false
+    end
+  end
+end
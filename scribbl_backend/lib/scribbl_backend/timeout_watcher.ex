@@ -33,7 +33,7 @@ defmodule ScribblBackend.TimeoutWatcher do
     opts = [
       host: host,
       port: String.to_integer(port),
-      password: System.get_env("REDIS_PASSWORD"),
+      password: ScribblBackend.ConfigCrypto.get_env("REDIS_PASSWORD"),
       name: :redix_pubsub
     ]
 
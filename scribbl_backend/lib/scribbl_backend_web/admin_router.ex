@@ -0,0 +1,43 @@
+defmodule ScribblBackendWeb.AdminRouter do
+  @moduledoc """
+  Routes of the internal admin API, protected by `ADMIN_API_TOKEN`.
+
+  Mounted at `/admin` by `ScribblBackendWeb.InternalRouter` on the internal
+  listener, and by `ScribblBackendWeb.Router` on the public listener when no
+  internal listener is configured.
+  """
+
+  use ScribblBackendWeb, :router
+
+  pipeline :admin do
+    plug :accepts, ["json"]
+    plug ScribblBackendWeb.Plugs.AdminAuth
+  end
+
+  # Scrapers ask for OpenMetrics rather than JSON
+  pipeline :admin_metrics do
+    plug ScribblBackendWeb.Plugs.AdminAuth
+  end
+
+  scope "/", ScribblBackendWeb do
+    pipe_through :admin
+
+    get "/loglevel", LogLevelController, :show
+    put "/loglevel", LogLevelController, :update
+
+    get "/read-only", ReadOnlyModeController, :show
+    put "/read-only", ReadOnlyModeController, :update
+
+    get "/rate-limit/exemptions", RateLimitExemptionController, :index
+    post "/rate-limit/exemptions", RateLimitExemptionController, :create
+    delete "/rate-limit/exemptions", RateLimitExemptionController, :delete
+
+    get "/health/transitions", HealthController, :transitions
+  end
+
+  scope "/", ScribblBackendWeb do
+    pipe_through :admin_metrics
+
+    get "/metrics", HealthController, :metrics
+  end
+end
@@ -8,10 +8,33 @@ defmodule ScribblBackendWeb.RoomChannel do
   alias ScribblBackend.WordManager
   alias ScribblBackend.KeyManager
   alias ScribblBackend.RedisHelper
+  alias ScribblBackend.PlayerName
+  alias ScribblBackend.ReadOnlyMode
+  alias ScribblBackendWeb.ErrorCodes
+  alias ScribblBackendWeb.RejectionMetrics
   require Logger
   require IO
 
+  # Client events that write game state to Redis, see handle_game_event/3
+  @mutating_events ~w(drawing drawing_clear new_message update_room_settings start_game start_turn skip_words voice_join voice_leave voice_mute vote_to_kick)
+
   def join("room:" <> room_id, %{"name" => name} = params, socket) do
+    # Joining creates rooms and adds players, so it's closed during maintenance
+    if ReadOnlyMode.enabled?() do
+      {:error, %{reason: "Scribbl is in read-only mode for maintenance", code: ErrorCodes.code(:read_only)}}
+    else
+      case PlayerName.validate(name) do
+        {:ok, name} ->
+          join_room(room_id, %{params | "name" => name}, socket)
+
+        {:error, reason} ->
+          RejectionMetrics.rejected("room:join", :invalid_name)
+          {:error, %{reason: reason, code: ErrorCodes.code(:bad_request)}}
+      end
+    end
+  end
+
+  defp join_room(room_id, %{"name" => name} = params, socket) do
     user_id = socket.assigns.user_id
     # Get avatar from params or use default
     avatar = Map.get(params, "avatar", "👤")
@@ -291,6 +314,15 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
+  # Game actions write to Redis, so they are refused during maintenance
+  def handle_in(event, payload, socket) when event in @mutating_events do
+    if ReadOnlyMode.enabled?() do
+      {:reply, {:error, %{reason: "Scribbl is in read-only mode for maintenance", code: ErrorCodes.code(:read_only)}}, socket}
+    else
+      handle_game_event(event, payload, socket)
+    end
+  end
+
   # NEW WebRTC Signaling Handlers
   def handle_in("webrtc_offer", %{"target_user_id" => target_user_id, "offer" => offer_sdp, "from_user_id" => from_user_id}, socket) do
     Logger.debug("Received webrtc_offer from #{from_user_id} for #{target_user_id}")
@@ -344,7 +376,70 @@ defmodule ScribblBackendWeb.RoomChannel do
   end
   # END NEW WebRTC Signaling Handlers
 
-  def handle_in("drawing_clear", %{}, socket) do
+  def handle_in("like_drawing", %{}, socket) do
+    user_id = socket.assigns.user_id
+
+    # Broadcast like event to all players in the room
+    Phoenix.PubSub.broadcast(
+      ScribblBackend.PubSub,
+      socket.topic,
+      %{
+        event: "drawing_liked",
+        payload: %{
+          "user_id" => user_id
+        }
+      }
+    )
+
+    {:noreply, socket}
+  end
+
+  def handle_in("dislike_drawing", %{}, socket) do
+    user_id = socket.assigns.user_id
+
+    # Broadcast dislike event to all players in the room
+    Phoenix.PubSub.broadcast(
+      ScribblBackend.PubSub,
+      socket.topic,
+      %{
+        event: "drawing_disliked",
+        payload: %{
+          "user_id" => user_id
+        }
+      }
+    )
+
+    {:noreply, socket}
+  end
+
+  def handle_in("get_kick_votes", %{"target_player_id" => target_player_id}, socket) do
+    room_id = String.split(socket.topic, ":") |> List.last()
+
+    # Check if target player is still in the room
+    {:ok, players} = PlayerManager.get_players(room_id)
+
+    if Enum.member?(players, target_player_id) do
+      case PlayerManager.get_kick_votes(room_id, target_player_id) do
+        {:ok, voters, required_votes} ->
+          {:reply, {:ok, %{
+            "voters" => voters,
+            "required_votes" => required_votes,
+            "votes_count" => length(voters)
+          }}, socket}
+
+        {:error, reason} ->
+          {:reply, {:error, %{"reason" => reason}}, socket}
+      end
+    else
+      # Player is no longer in the room
+      {:reply, {:error, %{"reason" => "Target player is not in the room"}}, socket}
+    end
+  end
+
+  # Unknown events and known events with a malformed payload
+  def handle_in(_event, _payload, socket), do: unmatched_event(socket)
+
+  defp handle_game_event("drawing_clear", %{}, socket) do
     # Get the room ID from the socket topic
     room_id = String.split(socket.topic, ":") |> List.last()
 
@@ -364,13 +459,13 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
-  def handle_in("new_message", %{"message" => message}, socket) do
+  defp handle_game_event("new_message", %{"message" => message}, socket) do
     GameFlow.handle_guess(message, socket)
     {:noreply, socket}
   end
 
   # handle drawing events
-  def handle_in(
+  defp handle_game_event(
         "drawing",
         %{
           "drawMode" => drawMode,
@@ -404,7 +499,7 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
-  def handle_in("update_room_settings", %{
+  defp handle_game_event("update_room_settings", %{
     "max_players" => max_players,
     "max_rounds" => max_rounds,
     "turn_time" => turn_time,
@@ -491,7 +586,7 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
-  def handle_in("start_game", _payload, socket) do
+  defp handle_game_event("start_game", _payload, socket) do
     room_id = String.split(socket.topic, ":") |> List.last()
     user_id = socket.assigns.user_id
 
@@ -509,7 +604,7 @@ defmodule ScribblBackendWeb.RoomChannel do
     end
   end
 
-  def handle_in("start_turn", %{"word" => word}, socket) do
+  defp handle_game_event("start_turn", %{"word" => word}, socket) do
     room_id = String.split(socket.topic, ":") |> List.last()
 
     # Get current drawer to verify that the request is from the drawer
@@ -541,7 +636,7 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
-  def handle_in("skip_words", %{}, socket) do
+  defp handle_game_event("skip_words", %{}, socket) do
     room_id = String.split(socket.topic, ":") |> List.last()
 
     # Get current drawer to verify that the request is from the drawer
@@ -590,7 +685,7 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
-  def handle_in("voice_join", %{}, socket) do
+  defp handle_game_event("voice_join", %{}, socket) do
     room_id = String.split(socket.topic, ":") |> List.last()
     user_id = socket.assigns.user_id
 
@@ -618,7 +713,7 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
-  def handle_in("voice_leave", %{}, socket) do
+  defp handle_game_event("voice_leave", %{}, socket) do
     room_id = String.split(socket.topic, ":") |> List.last()
     user_id = socket.assigns.user_id
 
@@ -648,7 +743,7 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
-  def handle_in("voice_mute", %{"muted" => muted}, socket) do
+  defp handle_game_event("voice_mute", %{"muted" => muted}, socket) do
     room_id = String.split(socket.topic, ":") |> List.last()
     user_id = socket.assigns.user_id
 
@@ -686,44 +781,8 @@ defmodule ScribblBackendWeb.RoomChannel do
     {:noreply, socket}
   end
 
-  def handle_in("like_drawing", %{}, socket) do
-    user_id = socket.assigns.user_id
-
-    # Broadcast like event to all players in the room
-    Phoenix.PubSub.broadcast(
-      ScribblBackend.PubSub,
-      socket.topic,
-      %{
-        event: "drawing_liked",
-        payload: %{
-          "user_id" => user_id
-        }
-      }
-    )
-
-    {:noreply, socket}
-  end
-
-  def handle_in("dislike_drawing", %{}, socket) do
-    user_id = socket.assigns.user_id
-
-    # Broadcast dislike event to all players in the room
-    Phoenix.PubSub.broadcast(
-      ScribblBackend.PubSub,
-      socket.topic,
-      %{
-        event: "drawing_disliked",
-        payload: %{
-          "user_id" => user_id
-        }
-      }
-    )
-
-    {:noreply, socket}
-  end
-
   # Add handlers for kick vote messages
-  def handle_in("vote_to_kick", %{"target_player_id" => target_player_id}, socket) do
+  defp handle_game_event("vote_to_kick", %{"target_player_id" => target_player_id}, socket) do
     room_id = String.split(socket.topic, ":") |> List.last()
     voter_id = socket.assigns.user_id
 
@@ -787,31 +846,11 @@ defmodule ScribblBackendWeb.RoomChannel do
     end
   end
 
-  def handle_in("get_kick_votes", %{"target_player_id" => target_player_id}, socket) do
-    room_id = String.split(socket.topic, ":") |> List.last()
-
-    # Check if target player is still in the room
-    {:ok, players} = PlayerManager.get_players(room_id)
-
-    if Enum.member?(players, target_player_id) do
-      case PlayerManager.get_kick_votes(room_id, target_player_id) do
-        {:ok, voters, required_votes} ->
-          {:reply, {:ok, %{
-            "voters" => voters,
-            "required_votes" => required_votes,
-            "votes_count" => length(voters)
-          }}, socket}
-
-        {:error, reason} ->
-          {:reply, {:error, %{"reason" => reason}}, socket}
-      end
-    else
-      # Player is no longer in the room
-      {:reply, {:error, %{"reason" => "Target player is not in the room"}}, socket}
-    end
-  end
+  defp handle_game_event(_event, _payload, socket), do: unmatched_event(socket)
 
-  def handle_in(_event, _payload, socket) do
+  # The event name is client controlled, so it isn't used as a metric tag
+  defp unmatched_event(socket) do
+    RejectionMetrics.rejected("room:event", :unmatched_event)
     {:reply, {:error, %{reason: "Unknown event"}}, socket}
   end
 
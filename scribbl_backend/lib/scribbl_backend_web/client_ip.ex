@@ -0,0 +1,131 @@
+defmodule ScribblBackendWeb.ClientIP do
+  @moduledoc """
+  Resolves the real client IP address of a request.
+
+  In production the backends sit behind Caddy, so `conn.remote_ip` is the
+  proxy's address. `X-Forwarded-For` is only honored when the request comes
+  from a trusted proxy, so clients can't spoof their address by sending the
+  header themselves. Trusted proxies are configured as a comma-separated list
+  of CIDRs in `TRUSTED_PROXIES` (default: loopback and private ranges, which
+  covers the Docker network Caddy runs on).
+  """
+
+  import Bitwise
+
+  @default_trusted_proxies ~w(127.0.0.0/8 10.0.0.0/8 172.16.0.0/12 192.168.0.0/16 ::1/128 fc00::/7)
+
+  @doc """
+  Get the client IP of a connection as an `:inet` address tuple.
+
+  ## Parameters
+    - `conn`: The Plug connection.
+  """
+  def get(conn) do
+    remote_ip = normalize(conn.remote_ip)
+    proxies = trusted_proxies()
+
+    if trusted?(remote_ip, proxies) do
+      conn
+      |> Plug.Conn.get_req_header("x-forwarded-for")
+      |> forwarded_client(remote_ip, proxies)
+    else
+      remote_ip
+    end
+  end
+
+  @doc """
+  Format an address tuple as a string, e.g. for Redis keys and logs.
+  """
+  def format(ip), do: ip |> :inet.ntoa() |> to_string()
+
+  @doc """
+  Check whether an address is within any of the given parsed CIDRs.
+
+  ## Parameters
+    - `ip`: The address tuple to check.
+    - `cidrs`: A list of CIDRs parsed with `parse_cidr/1`.
+  """
+  def trusted?(ip, cidrs) do
+    {value, bits} = to_integer(ip)
+
+    Enum.any?(cidrs, fn {network, network_bits, prefix} ->
+      network_bits == bits and value >>> (bits - prefix) == network >>> (bits - prefix)
+    end)
+  end
+
+  @doc """
+  Parse a CIDR string such as `"10.0.0.0/8"` or a bare address.
+
+  ## Returns
+    `{:ok, {network, bits, prefix}}` or `:error`.
+  """
+  def parse_cidr(cidr) do
+    {address, prefix} =
+      case String.split(String.trim(cidr), "/", parts: 2) do
+        [address, prefix] -> {address, Integer.parse(prefix)}
+        [address] -> {address, nil}
+      end
+
+    with {:ok, ip} <- :inet.parse_address(String.to_charlist(address)) do
+      {network, bits} = to_integer(ip)
+
+      case prefix do
+        nil -> {:ok, {network, bits, bits}}
+        {prefix, ""} when prefix in 0..bits -> {:ok, {network, bits, prefix}}
+        _ -> :error
+      end
+    else
+      _ -> :error
+    end
+  end
+
+  @doc """
+  Get the configured trusted proxies as parsed CIDRs. Invalid entries are ignored.
+  """
+  def trusted_proxies do
+    :scribbl_backend
+    |> Application.get_env(:trusted_proxies, @default_trusted_proxies)
+    |> Enum.flat_map(fn cidr ->
+      case parse_cidr(cidr) do
+        {:ok, parsed} -> [parsed]
+        :error -> []
+      end
+    end)
+  end
+
+  # Walk X-Forwarded-For from the right, skipping our own proxies.
+  # The first untrusted hop is the client.
+  defp forwarded_client(headers, remote_ip, proxies) do
+    headers
+    |> Enum.flat_map(&String.split(&1, ","))
+    |> Enum.map(&String.trim/1)
+    |> Enum.reverse()
+    |> Enum.reduce_while(remote_ip, fn entry, acc ->
+      case :inet.parse_address(String.to_charlist(entry)) do
+        {:ok, ip} ->
+          ip = normalize(ip)
+          if trusted?(ip, proxies), do: {:cont, ip}, else: {:halt, ip}
+
+        {:error, _reason} ->
+          {:halt, acc}
+      end
+    end)
+  end
+
+  # The endpoint binds on IPv6, so IPv4 clients arrive as ::ffff:a.b.c.d
+  defp normalize({0, 0, 0, 0, 0, 0xFFFF, high, low}),
+    do: {high >>> 8, high &&& 0xFF, low >>> 8, low &&& 0xFF}
+
+  # Requests over the Unix socket listener come from the same host
+  defp normalize({:local, _path}), do: {127, 0, 0, 1}
+  defp normalize(ip), do: ip
+
+  defp to_integer({_, _, _, _} = ip), do: {tuple_to_integer(ip, 8), 32}
+  defp to_integer(ip), do: {tuple_to_integer(ip, 16), 128}
+
+  defp tuple_to_integer(ip, width) do
+    ip
+    |> Tuple.to_list()
+    |> Enum.reduce(0, fn part, acc -> (acc <<< width) + part end)
+  end
+end
@@ -0,0 +1,120 @@
+defmodule ScribblBackendWeb.ConcurrencyLimiter do
+  @moduledoc """
+  Tracks in-flight HTTP requests per client IP in ETS, protecting the
+  service against slow-loris style clients that hold many requests open
+  at once (which a per-minute request counter doesn't catch).
+
+  Each request registers a slot entry and bumps a per-IP counter, both
+  removed when the request completes. Slow requests keep counting for as
+  long as they are open. A periodic sweep only evicts slots whose request
+  process died without releasing them, e.g. when it was killed.
+  """
+
+  use GenServer
+
+  @table :scribbl_in_flight_requests
+  @sweep_interval_ms 30_000
+
+  ## Client API
+
+  def start_link(_opts) do
+    GenServer.start_link(__MODULE__, %{}, name: __MODULE__)
+  end
+
+  @doc """
+  Register an in-flight request for an IP if it is below the limit.
+
+  ## Parameters
+    - `ip`: The client IP address tuple.
+    - `max_in_flight`: The maximum number of concurrent requests allowed for the IP.
+
+  ## Returns
+    `{:ok, ref}` to pass to `release/2` when the request completes,
+    or `{:error, :limit_reached}`.
+  """
+  def acquire(ip, max_in_flight) do
+    ref = make_ref()
+
+    # Count first, then check, so concurrent requests can't both slip under the limit
+    count = :ets.update_counter(@table, {:count, ip}, 1, {{:count, ip}, 0})
+    :ets.insert(@table, {{:slot, ip, ref}, self()})
+
+    if count > max_in_flight do
+      release(ip, ref)
+      {:error, :limit_reached}
+    else
+      {:ok, ref}
+    end
+  end
+
+  @doc """
+  Release a request slot acquired with `acquire/2`. Releasing a slot twice
+  (e.g. by the request and the sweep) only decrements the count once.
+  """
+  def release(ip, ref) do
+    case :ets.take(@table, {:slot, ip, ref}) do
+      [_slot] -> :ets.update_counter(@table, {:count, ip}, -1, {{:count, ip}, 1})
+      [] -> :ok
+    end
+
+    :ok
+  end
+
+  @doc """
+  Get the number of in-flight requests for an IP.
+  """
+  def in_flight(ip) do
+    case :ets.lookup(@table, {:count, ip}) do
+      [{_key, count}] -> count
+      [] -> 0
+    end
+  end
+
+  @doc """
+  Evict the slots of dead request processes now, instead of waiting for
+  the periodic sweep.
+  """
+  def sweep do
+    GenServer.call(__MODULE__, :sweep)
+  end
+
+  ## Server Callbacks
+
+  @impl true
+  def init(state) do
+    :ets.new(@table, [:set, :public, :named_table, read_concurrency: true, write_concurrency: true])
+    schedule_sweep()
+    {:ok, state}
+  end
+
+  @impl true
+  def handle_call(:sweep, _from, state) do
+    evict_dead_slots()
+    {:reply, :ok, state}
+  end
+
+  @impl true
+  def handle_info(:sweep, state) do
+    evict_dead_slots()
+    schedule_sweep()
+    {:noreply, state}
+  end
+
+  def handle_info(_msg, state) do
+    {:noreply, state}
+  end
+
+  defp evict_dead_slots do
+    for [ip, ref, pid] <- :ets.match(@table, {{:slot, :"$1", :"$2"}, :"$3"}),
+        not Process.alive?(pid) do
+      release(ip, ref)
+    end
+
+    # Drop idle counters so the table doesn't grow with every client ever seen
+    :ets.select_delete(@table, [{{{:count, :_}, 0}, [], [true]}])
+  end
+
+  defp schedule_sweep do
+    Process.send_after(self(), :sweep, @sweep_interval_ms)
+  end
+end
@@ -5,6 +5,8 @@ defmodule ScribblBackendWeb.ErrorJSON do
   See config/config.exs.
   """
 
+  alias ScribblBackendWeb.ErrorCodes
+
   # If you want to customize a particular status code,
   # you may add your own clauses, such as:
   #
@@ -14,8 +16,22 @@ defmodule ScribblBackendWeb.ErrorJSON do
 
   # By default, Phoenix returns the status message from
   # the template name. For example, "404.json" becomes
-  # "Not Found".
+  # "Not Found". The machine-readable code is derived
+  # from the status, see ScribblBackendWeb.ErrorCodes.
   def render(template, _assigns) do
-    %{errors: %{detail: Phoenix.Controller.status_message_from_template(template)}}
+    %{
+      errors: %{
+        detail: Phoenix.Controller.status_message_from_template(template),
+        code: template |> status_from_template() |> ErrorCodes.for_status()
+      }
+    }
+  end
+
+  # "404.json" -> 404
+  defp status_from_template(template) do
+    case Integer.parse(template) do
+      {status, _rest} -> status
+      :error -> 500
+    end
   end
 end
@@ -0,0 +1,44 @@
+defmodule ScribblBackendWeb.HealthController do
+  use ScribblBackendWeb, :controller
+
+  alias ScribblBackend.HealthMonitor
+  alias ScribblBackendWeb.HealthMetrics
+  alias ScribblBackendWeb.OpenMetrics
+  alias ScribblBackendWeb.RejectionMetrics
+
+  @doc """
+  Internal endpoint exposing dependency health, its transition counts and
+  rejected request counts in the OpenMetrics text format.
+
+  Returns:
+  - 200: OpenMetrics exposition ending with `# EOF`
+  """
+  def metrics(conn, _params) do
+    %{counts: counts} = HealthMonitor.history()
+
+    conn
+    |> put_resp_content_type(OpenMetrics.content_type(), nil)
+    |> send_resp(
+      :ok,
+      OpenMetrics.document([
+        HealthMetrics.families(HealthMonitor.all(), counts),
+        RejectionMetrics.families(RejectionMetrics.counts())
+      ])
+    )
+  end
+
+  @doc """
+  Internal endpoint listing the recent dependency status changes of the
+  serving node, newest first.
+
+  Returns:
+  - 200: {transitions: [{dependency: "redis", from: "healthy", to: "degraded", cause: "PING took 312ms", at: "2025-01-01T00:00:00Z"}]}
+  """
+  def transitions(conn, _params) do
+    %{transitions: transitions} = HealthMonitor.history()
+
+    conn
+    |> put_status(:ok)
+    |> json(%{transitions: transitions})
+  end
+end
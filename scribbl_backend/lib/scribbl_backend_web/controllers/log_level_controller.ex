@@ -0,0 +1,57 @@
+defmodule ScribblBackendWeb.LogLevelController do
+  use ScribblBackendWeb, :controller
+
+  alias ScribblBackend.LogLevel
+  alias ScribblBackendWeb.ErrorCodes
+  alias ScribblBackendWeb.RejectionMetrics
+
+  @doc """
+  Internal endpoint to read the current log level of the serving node.
+
+  Returns:
+  - 200: {level: "info", base_level: "info"}
+  """
+  def show(conn, _params) do
+    conn
+    |> put_status(:ok)
+    |> json(LogLevel.current())
+  end
+
+  @doc """
+  Internal endpoint to change the log level on all nodes. The level
+  reverts automatically after `duration_seconds` (default 600, max 3600).
+
+  Returns:
+  - 200: {level: "debug", revert_at: "2025-01-01T00:10:00Z"}
+  - 400: {error: "...", code: "BAD_REQUEST"} if the level or duration is invalid
+  """
+  def update(conn, %{"level" => level} = params) do
+    duration_ms =
+      case Map.get(params, "duration_seconds", 600) do
+        seconds when is_integer(seconds) -> seconds * 1000
+        _ -> nil
+      end
+
+    case LogLevel.set_level(level, duration_ms) do
+      {:ok, result} ->
+        conn
+        |> put_status(:ok)
+        |> json(result)
+
+      {:error, reason} ->
+        RejectionMetrics.rejected("PUT /admin/loglevel", :invalid_field)
+
+        conn
+        |> put_status(:bad_request)
+        |> json(%{error: reason, code: ErrorCodes.code(:bad_request)})
+    end
+  end
+
+  def update(conn, _params) do
+    RejectionMetrics.rejected("PUT /admin/loglevel", :missing_field)
+
+    conn
+    |> put_status(:bad_request)
+    |> json(%{error: "Missing level", code: ErrorCodes.code(:bad_request)})
+  end
+end
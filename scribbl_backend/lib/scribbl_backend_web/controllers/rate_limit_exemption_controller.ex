@@ -0,0 +1,86 @@
+defmodule ScribblBackendWeb.RateLimitExemptionController do
+  use ScribblBackendWeb, :controller
+
+  alias ScribblBackendWeb.ErrorCodes
+  alias ScribblBackendWeb.RateLimitExemptions
+  alias ScribblBackendWeb.RejectionMetrics
+
+  @doc """
+  Internal endpoint to list the runtime-managed rate limit exemptions.
+  Exemptions from `RATE_LIMIT_EXEMPT_IPS` are not included.
+
+  Returns:
+  - 200: {exemptions: ["203.0.113.0/24"]}
+  - 503: {error: "...", code: "SERVICE_UNAVAILABLE"} if Redis could not be queried
+  """
+  def index(conn, _params) do
+    case RateLimitExemptions.list() do
+      {:ok, entries} ->
+        conn
+        |> put_status(:ok)
+        |> json(%{exemptions: Enum.sort(entries)})
+
+      {:error, _reason} ->
+        exemptions_unavailable(conn)
+    end
+  end
+
+  @doc """
+  Internal endpoint to exempt an IP address or CIDR from rate limiting.
+
+  Returns:
+  - 201: {entry: "203.0.113.0/24"}
+  - 400: {error: "...", code: "BAD_REQUEST"} if the entry is not a valid address or CIDR
+  - 503: {error: "...", code: "SERVICE_UNAVAILABLE"} if Redis could not be updated
+  """
+  def create(conn, params) do
+    entry = Map.get(params, "entry")
+
+    case RateLimitExemptions.add(entry) do
+      :ok ->
+        conn
+        |> put_status(:created)
+        |> json(%{entry: String.trim(entry)})
+
+      {:error, :unavailable} ->
+        exemptions_unavailable(conn)
+
+      {:error, reason} ->
+        RejectionMetrics.rejected("POST /admin/rate-limit/exemptions", :invalid_field)
+
+        conn
+        |> put_status(:bad_request)
+        |> json(%{error: reason, code: ErrorCodes.code(:bad_request)})
+    end
+  end
+
+  @doc """
+  Internal endpoint to remove an exemption, passed as the `entry` parameter.
+
+  Returns:
+  - 204 on success
+  - 400: {error: "Missing entry", code: "BAD_REQUEST"}
+  - 503: {error: "...", code: "SERVICE_UNAVAILABLE"} if Redis could not be updated
+  """
+  def delete(conn, %{"entry" => entry}) when is_binary(entry) do
+    case RateLimitExemptions.remove(entry) do
+      :ok -> send_resp(conn, :no_content, "")
+      {:error, :unavailable} -> exemptions_unavailable(conn)
+    end
+  end
+
+  def delete(conn, _params) do
+    RejectionMetrics.rejected("DELETE /admin/rate-limit/exemptions", :missing_field)
+
+    conn
+    |> put_status(:bad_request)
+    |> json(%{error: "Missing entry", code: ErrorCodes.code(:bad_request)})
+  end
+
+  # Redis errors are structs that can't be serialized, so don't leak them
+  defp exemptions_unavailable(conn) do
+    conn
+    |> put_status(:service_unavailable)
+    |> json(%{error: "Exemptions unavailable", code: ErrorCodes.code(:service_unavailable)})
+  end
+end
@@ -0,0 +1,42 @@
+defmodule ScribblBackendWeb.ReadOnlyModeController do
+  use ScribblBackendWeb, :controller
+
+  alias ScribblBackend.ReadOnlyMode
+  alias ScribblBackendWeb.ErrorCodes
+  alias ScribblBackendWeb.RejectionMetrics
+
+  @doc """
+  Internal endpoint to check whether the serving node is in read-only mode.
+
+  Returns:
+  - 200: {enabled: false}
+  """
+  def show(conn, _params) do
+    conn
+    |> put_status(:ok)
+    |> json(%{enabled: ReadOnlyMode.enabled?()})
+  end
+
+  @doc """
+  Internal endpoint to enable or disable read-only mode on all nodes.
+
+  Returns:
+  - 200: {enabled: true}
+  - 400: {error: "...", code: "BAD_REQUEST"} if `enabled` is not a boolean
+  """
+  def update(conn, %{"enabled" => enabled}) when is_boolean(enabled) do
+    ReadOnlyMode.set(enabled)
+
+    conn
+    |> put_status(:ok)
+    |> json(%{enabled: enabled})
+  end
+
+  def update(conn, _params) do
+    RejectionMetrics.rejected("PUT /admin/read-only", :invalid_field)
+
+    conn
+    |> put_status(:bad_request)
+    |> json(%{error: "enabled must be true or false", code: ErrorCodes.code(:bad_request)})
+  end
+end
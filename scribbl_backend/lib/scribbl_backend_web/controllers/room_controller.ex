@@ -2,6 +2,7 @@ defmodule ScribblBackendWeb.RoomController do
   use ScribblBackendWeb, :controller
 
   alias ScribblBackend.GameState
+  alias ScribblBackendWeb.ErrorCodes
 
   # List of adjectives for room ID generation
   @adjectives [
@@ -56,7 +57,8 @@ defmodule ScribblBackendWeb.RoomController do
 
   Returns:
   - 200: {room_id: "room_123"} if a room is found
-  - 404: {error: "No available public rooms"} if no rooms are available
+  - 404: {error: "No public rooms available", code: "NO_PUBLIC_ROOMS"} if no rooms are available
+  - 503: {error: "Room lookup unavailable", code: "SERVICE_UNAVAILABLE"} if Redis could not be queried
   """
   def join_random(conn, _params) do
     case GameState.find_random_public_room() do
@@ -65,10 +67,16 @@ defmodule ScribblBackendWeb.RoomController do
         |> put_status(:ok)
         |> json(%{room_id: room_id})
 
-      {:error, reason} ->
+      {:error, reason} when is_binary(reason) ->
         conn
         |> put_status(:not_found)
-        |> json(%{error: reason})
+        |> json(%{error: reason, code: ErrorCodes.code(:no_public_rooms)})
+
+      {:error, _reason} ->
+        # Redis errors are structs that can't be serialized, so don't leak them
+        conn
+        |> put_status(:service_unavailable)
+        |> json(%{error: "Room lookup unavailable", code: ErrorCodes.code(:service_unavailable)})
     end
   end
 
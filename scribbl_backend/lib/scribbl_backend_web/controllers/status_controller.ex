@@ -0,0 +1,21 @@
+defmodule ScribblBackendWeb.StatusController do
+  use ScribblBackendWeb, :controller
+
+  alias ScribblBackend.HealthMonitor
+  alias ScribblBackend.ReadOnlyMode
+  alias ScribblBackendWeb.PublicStatus
+
+  @doc """
+  Public endpoint reporting coarse service health for status pages.
+  Served from cached health checks, so it stays cheap to poll.
+
+  Returns:
+  - 200: {status: "operational", components: {api: "operational", game_rooms: "operational"}}
+  """
+  def show(conn, _params) do
+    conn
+    |> put_resp_header("cache-control", "public, max-age=5")
+    |> put_status(:ok)
+    |> json(PublicStatus.build(HealthMonitor.all(), ReadOnlyMode.enabled?()))
+  end
+end
@@ -0,0 +1,134 @@
+defmodule ScribblBackendWeb.Deprecations do
+  @moduledoc """
+  Registry of API endpoints scheduled for removal.
+
+  Entries are configured without code changes as a JSON array in
+  `API_DEPRECATIONS`, for example:
+
+      [{"method": "GET", "path": "/api/rooms/generate-id",
+        "sunset": "2027-01-01", "link": "https://scribbl.club/docs/api-v2",
+        "message": "Use /v2/rooms/generate-id instead"}]
+
+  Only `path` is required. A `path` ending in `*` matches any path with that
+  prefix, and an entry without `method` matches every method. `deprecated_at`
+  and `sunset` accept ISO 8601 dates or datetimes.
+
+  Matching requests get `Deprecation`, `Sunset` and `Link` headers, and a
+  `warning` field in JSON responses, see `ScribblBackendWeb.Plugs.Deprecation`.
+  """
+
+  @doc """
+  Parse and validate the `API_DEPRECATIONS` JSON. Raises on invalid config so
+  mistakes are caught at startup rather than silently ignored.
+
+  ## Returns
+    A list of deprecation entries.
+  """
+  def parse!(json) do
+    case Jason.decode!(json) do
+      entries when is_list(entries) -> Enum.map(entries, &parse_entry!/1)
+      _ -> raise ArgumentError, "API_DEPRECATIONS must be a JSON array"
+    end
+  end
+
+  @doc """
+  Find the deprecation entry for a request, if any.
+
+  ## Parameters
+    - `method`: The HTTP method, e.g. `"GET"`.
+    - `path`: The request path.
+    - `entries`: The entries to search (default: the configured ones).
+
+  ## Returns
+    The matching entry or `nil`.
+  """
+  def lookup(method, path, entries \\ entries()) do
+    Enum.find(entries, fn entry ->
+      entry.method in [nil, method] and path_matches?(entry, path)
+    end)
+  end
+
+  @doc """
+  Get the configured deprecation entries.
+  """
+  def entries do
+    Application.get_env(:scribbl_backend, :deprecations, [])
+  end
+
+  @doc """
+  Build the response headers announcing a deprecation.
+
+  ## Returns
+    A list of `{name, value}` header tuples.
+  """
+  def headers(entry) do
+    deprecation =
+      case entry.deprecated_at do
+        nil -> "true"
+        at -> "@#{DateTime.to_unix(at)}"
+      end
+
+    [{"deprecation", deprecation}] ++
+      if(entry.sunset, do: [{"sunset", http_date(entry.sunset)}], else: []) ++
+      if(entry.link, do: [{"link", ~s(<#{entry.link}>; rel="deprecation")}], else: [])
+  end
+
+  @doc """
+  Build the human-readable warning added to JSON responses.
+  """
+  def warning(%{message: message}) when is_binary(message), do: message
+
+  def warning(%{sunset: nil}), do: "This endpoint is deprecated"
+
+  def warning(%{sunset: sunset}) do
+    "This endpoint is deprecated and will be removed on #{DateTime.to_date(sunset)}"
+  end
+
+  defp parse_entry!(%{"path" => path} = entry) when is_binary(path) do
+    prefix? = String.ends_with?(path, "*")
+
+    %{
+      method: entry |> Map.get("method") |> upcase(),
+      path: String.trim_trailing(path, "*"),
+      prefix?: prefix?,
+      deprecated_at: parse_time!(entry["deprecated_at"]),
+      sunset: parse_time!(entry["sunset"]),
+      link: entry["link"],
+      message: entry["message"]
+    }
+  end
+
+  defp parse_entry!(entry) do
+    raise ArgumentError, "API_DEPRECATIONS entry is missing a path: #{inspect(entry)}"
+  end
+
+  defp path_matches?(%{prefix?: true, path: prefix}, path), do: String.starts_with?(path, prefix)
+  defp path_matches?(%{path: expected}, path), do: expected == path
+
+  defp upcase(nil), do: nil
+  defp upcase(method), do: String.upcase(method)
+
+  defp parse_time!(nil), do: nil
+
+  defp parse_time!(value) when is_binary(value) do
+    case DateTime.from_iso8601(value) do
+      {:ok, datetime, _offset} ->
+        datetime
+
+      {:error, _reason} ->
+        case Date.from_iso8601(value) do
+          {:ok, date} -> DateTime.new!(date, ~T[00:00:00], "Etc/UTC")
+          {:error, _reason} -> raise ArgumentError, "invalid date in API_DEPRECATIONS: #{inspect(value)}"
+        end
+    end
+  end
+
+  defp parse_time!(value) do
+    raise ArgumentError, "invalid date in API_DEPRECATIONS: #{inspect(value)}"
+  end
+
+  # RFC 9110 IMF-fixdate, as required by the Sunset header
+  defp http_date(datetime) do
+    Calendar.strftime(datetime, "%a, %d %b %Y %H:%M:%S GMT")
+  end
+end
@@ -1,20 +1,26 @@
 defmodule ScribblBackendWeb.Endpoint do
   use Phoenix.Endpoint, otp_app: :scribbl_backend
 
+  alias ScribblBackendWeb.OriginPolicy
+
   def cors_origins do
-    Application.get_env(:scribbl_backend, :cors_allowed_origins, ["http://localhost:3000"])
+    OriginPolicy.current().allowed_origins
   end
 
   # The session will be stored in the cookie and signed,
   # this means its contents can be read but not tampered with.
   # Set :encryption_salt if you would also like to encrypt it.
+  # Cookie scope (domain, SameSite, Secure) comes from the origin policy.
   @session_options [
     store: :cookie,
     key: "_scribbl_backend_key",
-    signing_salt: "2gw+BEy6",
-    same_site: "Lax"
+    signing_salt: "2gw+BEy6"
   ]
 
+  def session_options do
+    @session_options ++ OriginPolicy.cookie_options(OriginPolicy.current())
+  end
+
   socket "/socket", ScribblBackendWeb.UserSocket,
   # skip origin verification for now
     websocket: [check_origin: false, timeout: 60_000, compress: true],
@@ -22,8 +28,8 @@ defmodule ScribblBackendWeb.Endpoint do
 
 
   socket "/live", Phoenix.LiveView.Socket,
-    websocket: [connect_info: [session: @session_options]],
-    longpoll: [connect_info: [session: @session_options]]
+    websocket: [connect_info: [session: {__MODULE__, :session_options, []}]],
+    longpoll: [connect_info: [session: {__MODULE__, :session_options, []}]]
 
   # Serve at "/" the static files from "priv/static" directory.
   #
@@ -52,15 +58,12 @@ defmodule ScribblBackendWeb.Endpoint do
   plug CORSPlug,
     origin: &ScribblBackendWeb.Endpoint.cors_origins/0,
     methods: ["GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"],
-    headers: ["Content-Type", "Authorization", "Accept", "Origin", "User-Agent", "DNT", "Cache-Control", "X-Mx-ReqToken", "Keep-Alive", "X-Requested-With", "If-Modified-Since"]
-
-  plug Plug.Parsers,
-    parsers: [:urlencoded, :multipart, :json],
-    pass: ["*/*"],
-    json_decoder: Phoenix.json_library()
+    headers: ["Content-Type", "Authorization", "Accept", "Origin", "User-Agent", "DNT", "Cache-Control", "X-Mx-ReqToken", "Keep-Alive", "X-Requested-With", "If-Modified-Since"],
+    # Let the frontend read rate limit state to throttle itself
+    expose: ["X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"]
 
-  plug Plug.MethodOverride
-  plug Plug.Head
-  plug Plug.Session, @session_options
-  plug ScribblBackendWeb.Router
+  # Cap in-flight requests per client IP before reading request bodies.
+  # Parsing, sessions and routing run inside the limit so a raising plug
+  # can't leak the slot.
+  plug ScribblBackendWeb.Plugs.ConcurrencyLimit, plug: ScribblBackendWeb.RequestPipeline
 end
@@ -0,0 +1,96 @@
+defmodule ScribblBackendWeb.ErrorCodes do
+  @moduledoc """
+  Catalog of stable, machine-readable error codes returned by the REST API.
+
+  Every JSON error response carries one of these codes alongside its
+  human-readable message, so clients can branch on the code instead of
+  parsing the message text (which may change at any time).
+
+  ## Catalog
+    - `BAD_REQUEST` (400): The request could not be parsed or was invalid.
+    - `UNAUTHORIZED` (401): Authentication is missing or invalid.
+    - `FORBIDDEN` (403): The caller is not allowed to perform the action.
+    - `NOT_FOUND` (404): The route or resource does not exist.
+    - `METHOD_NOT_ALLOWED` (405): The route does not accept this HTTP method.
+    - `NOT_ACCEPTABLE` (406): The requested response format is not supported.
+    - `PAYLOAD_TOO_LARGE` (413): The request body exceeds the allowed size.
+    - `UNSUPPORTED_MEDIA_TYPE` (415): The request content type is not supported.
+    - `RATE_LIMITED` (429): The caller has sent too many requests.
+    - `INTERNAL_ERROR` (500): An unexpected server error occurred.
+    - `SERVICE_UNAVAILABLE` (503): A dependency (e.g. Redis) is unavailable.
+    - `NO_PUBLIC_ROOMS` (404): No public room with free slots is available.
+    - `READ_ONLY` (503): The service is in read-only mode for maintenance.
+
+  New codes may be added, but existing codes are never renamed or removed.
+  """
+
+  @catalog %{
+    bad_request: "BAD_REQUEST",
+    unauthorized: "UNAUTHORIZED",
+    forbidden: "FORBIDDEN",
+    not_found: "NOT_FOUND",
+    method_not_allowed: "METHOD_NOT_ALLOWED",
+    not_acceptable: "NOT_ACCEPTABLE",
+    payload_too_large: "PAYLOAD_TOO_LARGE",
+    unsupported_media_type: "UNSUPPORTED_MEDIA_TYPE",
+    rate_limited: "RATE_LIMITED",
+    internal_error: "INTERNAL_ERROR",
+    service_unavailable: "SERVICE_UNAVAILABLE",
+    no_public_rooms: "NO_PUBLIC_ROOMS",
+    read_only: "READ_ONLY"
+  }
+
+  @status_codes %{
+    400 => :bad_request,
+    401 => :unauthorized,
+    403 => :forbidden,
+    404 => :not_found,
+    405 => :method_not_allowed,
+    406 => :not_acceptable,
+    413 => :payload_too_large,
+    415 => :unsupported_media_type,
+    429 => :rate_limited,
+    500 => :internal_error,
+    503 => :service_unavailable
+  }
+
+  @doc """
+  Get the error code string for a catalog entry.
+
+  ## Parameters
+    - `name`: The catalog entry, e.g. `:no_public_rooms`.
+
+  ## Examples
+      iex> ScribblBackendWeb.ErrorCodes.code(:no_public_rooms)
+      "NO_PUBLIC_ROOMS"
+  """
+  def code(name), do: Map.fetch!(@catalog, name)
+
+  @doc """
+  Get the generic error code for an HTTP status.
+  Statuses without a dedicated entry fall back to `BAD_REQUEST` for 4xx
+  and `INTERNAL_ERROR` for everything else.
+
+  ## Parameters
+    - `status`: The HTTP status as an integer.
+
+  ## Examples
+      iex> ScribblBackendWeb.ErrorCodes.for_status(404)
+      "NOT_FOUND"
+
+      iex> ScribblBackendWeb.ErrorCodes.for_status(418)
+      "BAD_REQUEST"
+  """
+  def for_status(status) when is_integer(status) do
+    case Map.fetch(@status_codes, status) do
+      {:ok, name} -> code(name)
+      :error when status in 400..499 -> code(:bad_request)
+      :error -> code(:internal_error)
+    end
+  end
+
+  @doc """
+  Get every code in the catalog, e.g. for documentation or client generation.
+  """
+  def all, do: @catalog |> Map.values() |> Enum.sort()
+end
@@ -0,0 +1,60 @@
+defmodule ScribblBackendWeb.HealthMetrics do
+  @moduledoc """
+  Renders dependency health and its transition counts in the OpenMetrics
+  text format, for scraping by Prometheus-compatible collectors.
+  """
+
+  alias ScribblBackendWeb.OpenMetrics
+
+  @statuses [:healthy, :degraded, :unhealthy]
+
+  @doc """
+  Render the health metrics.
+
+  ## Parameters
+    - `health`: The map returned by `ScribblBackend.HealthMonitor.all/0`.
+    - `counts`: The transition counts from `ScribblBackend.HealthMonitor.history/0`.
+
+  ## Returns
+    The exposition as a string, terminated by `# EOF`.
+  """
+  def render(health, counts), do: OpenMetrics.document(families(health, counts))
+
+  @doc """
+  Render the health metric families without the `# EOF` terminator, to be
+  combined with other families. Takes the same parameters as `render/2`.
+  """
+  def families(health, counts) do
+    health = Enum.sort(health)
+
+    status =
+      for {dependency, %{status: current}} <- health, status <- @statuses do
+        labels = [dependency: dependency, scribbl_health_status: status]
+        OpenMetrics.sample("scribbl_health_status", labels, if(status == current, do: 1, else: 0))
+      end
+
+    since =
+      for {dependency, %{since: since}} <- health do
+        seconds = DateTime.to_unix(since, :millisecond) / 1000
+        OpenMetrics.sample("scribbl_health_status_since_seconds", [dependency: dependency], seconds)
+      end
+
+    transitions =
+      for {{dependency, from, to}, count} <- Enum.sort(counts) do
+        OpenMetrics.sample("scribbl_health_transitions_total", [dependency: dependency, from: from, to: to], count)
+      end
+
+    [
+      "# TYPE scribbl_health_status stateset\n",
+      "# HELP scribbl_health_status Current health status of each dependency.\n",
+      status,
+      "# TYPE scribbl_health_status_since_seconds gauge\n",
+      "# UNIT scribbl_health_status_since_seconds seconds\n",
+      "# HELP scribbl_health_status_since_seconds Unix time of the last status change of each dependency.\n",
+      since,
+      "# TYPE scribbl_health_transitions counter\n",
+      "# HELP scribbl_health_transitions Health status changes per dependency since the node started.\n",
+      transitions
+    ]
+  end
+end
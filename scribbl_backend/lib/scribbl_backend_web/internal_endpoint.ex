@@ -0,0 +1,23 @@
+defmodule ScribblBackendWeb.InternalEndpoint do
+  @moduledoc """
+  The internal listener, serving the admin API on its own port
+  (`INTERNAL_PORT`) so it never has to be exposed through the public load
+  balancer. It also backs the Unix socket listener (`UNIX_SOCKET_PATH`).
+
+  Unlike the public endpoint it has no CORS, sessions or client rate limits,
+  and it can use its own TLS certificate.
+  """
+
+  use Phoenix.Endpoint, otp_app: :scribbl_backend
+
+  plug Plug.RequestId
+  plug Plug.Telemetry, event_prefix: [:phoenix, :internal_endpoint]
+
+  plug ScribblBackendWeb.Plugs.Parsers,
+    parsers: [:urlencoded, :json],
+    pass: ["*/*"],
+    json_decoder: Phoenix.json_library(),
+    router: ScribblBackendWeb.InternalRouter
+
+  plug ScribblBackendWeb.InternalRouter
+end
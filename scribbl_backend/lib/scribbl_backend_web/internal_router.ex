@@ -0,0 +1,5 @@
+defmodule ScribblBackendWeb.InternalRouter do
+  use ScribblBackendWeb, :router
+
+  forward "/admin", ScribblBackendWeb.AdminRouter
+end
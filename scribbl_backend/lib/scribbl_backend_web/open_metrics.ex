@@ -0,0 +1,46 @@
+defmodule ScribblBackendWeb.OpenMetrics do
+  @moduledoc """
+  Helpers for rendering metrics in the OpenMetrics text format, shared by
+  the metric families served from `/admin/metrics`.
+  """
+
+  @content_type "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+  @doc """
+  The content type of an OpenMetrics exposition.
+  """
+  def content_type, do: @content_type
+
+  @doc """
+  Join metric families into an exposition terminated by `# EOF`.
+
+  ## Parameters
+    - `families`: The rendered families as iodata.
+  """
+  def document(families), do: IO.iodata_to_binary([families, "# EOF\n"])
+
+  @doc """
+  Render one sample line.
+
+  ## Parameters
+    - `name`: The sample name, e.g. `"scribbl_health_transitions_total"`.
+    - `labels`: A keyword list of label names and values.
+    - `value`: An integer or float.
+  """
+  def sample(name, labels, value) do
+    labels = Enum.map_join(labels, ",", fn {key, label} -> ~s(#{key}="#{escape(label)}") end)
+    [name, "{", labels, "} ", format_value(value), "\n"]
+  end
+
+  # Label values are client-visible strings, e.g. route patterns
+  defp escape(label) do
+    label
+    |> to_string()
+    |> String.replace("\\", "\\\\")
+    |> String.replace("\"", "\\\"")
+    |> String.replace("\n", "\\n")
+  end
+
+  defp format_value(value) when is_integer(value), do: Integer.to_string(value)
+  defp format_value(value) when is_float(value), do: :erlang.float_to_binary(value, decimals: 3)
+end
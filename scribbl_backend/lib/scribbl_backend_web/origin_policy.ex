@@ -0,0 +1,107 @@
+defmodule ScribblBackendWeb.OriginPolicy do
+  @moduledoc """
+  The browser-facing origin settings of an environment: which origins may
+  call the API (CORS) and how the session cookie is scoped.
+
+  They are built together from the environment in `config/runtime.exs` and
+  validated at startup, so a mismatch (e.g. `SameSite=None` without `Secure`,
+  or a cookie domain that doesn't cover the API host) fails the boot instead
+  of silently breaking browsers.
+
+  ## Environment
+    - `CORS_ALLOWED_ORIGINS`: Comma-separated origins, e.g. `https://scribbl.club`,
+      or `*` for any origin (not with `COOKIE_SAME_SITE=None`).
+    - `COOKIE_DOMAIN`: Domain of the session cookie (default: the API host only).
+    - `COOKIE_SAME_SITE`: `Lax` (default), `Strict` or `None`.
+  """
+
+  defstruct allowed_origins: ["http://localhost:3000"],
+            cookie_domain: nil,
+            cookie_same_site: "Lax",
+            cookie_secure: false
+
+  @same_site_values ~w(Lax Strict None)
+
+  @doc """
+  Build and validate a policy. Raises `ArgumentError` describing the first
+  problem found.
+
+  ## Parameters
+    - `opts`: `:allowed_origins`, `:cookie_domain`, `:cookie_same_site` and
+      `:cookie_secure`, plus the `:api_host` the cookie domain must cover.
+      `nil` or empty values use the defaults.
+  """
+  def new!(opts) do
+    # Unset or empty env values fall back to the defaults
+    opts = Enum.reject(opts, fn {_key, value} -> value in [nil, ""] end)
+    policy = struct!(__MODULE__, Keyword.delete(opts, :api_host))
+
+    Enum.each(policy.allowed_origins, &validate_origin!(&1, policy))
+    validate_same_site!(policy)
+    validate_cookie_domain!(policy.cookie_domain, Keyword.get(opts, :api_host))
+
+    policy
+  end
+
+  @doc """
+  Get the configured policy, or the development defaults.
+  """
+  def current do
+    Application.get_env(:scribbl_backend, :origin_policy, %__MODULE__{})
+  end
+
+  @doc """
+  Get the `Plug.Session` cookie options for a policy.
+  """
+  def cookie_options(policy) do
+    [same_site: policy.cookie_same_site, secure: policy.cookie_secure] ++
+      if(policy.cookie_domain, do: [domain: policy.cookie_domain], else: [])
+  end
+
+  # A wildcard can't carry credentials, so it would silently break a
+  # cross-site session cookie
+  defp validate_origin!("*", %{cookie_same_site: "None"}) do
+    raise ArgumentError,
+          "CORS origin \"*\" can't be used with COOKIE_SAME_SITE=None, list the allowed origins instead"
+  end
+
+  defp validate_origin!("*", _policy), do: :ok
+
+  # CORS compares origins as exact strings, so anything beyond
+  # scheme://host[:port] (a path or trailing slash) never matches
+  defp validate_origin!(origin, _policy) do
+    case URI.parse(origin) do
+      %URI{scheme: scheme, host: host, path: nil, query: nil, fragment: nil, userinfo: nil}
+      when scheme in ["http", "https"] and is_binary(host) and host != "" ->
+        :ok
+
+      _ ->
+        raise ArgumentError,
+              "invalid CORS origin #{inspect(origin)}, expected \"*\" or scheme://host[:port] without a path"
+    end
+  end
+
+  defp validate_same_site!(%{cookie_same_site: same_site}) when same_site not in @same_site_values do
+    raise ArgumentError,
+          "invalid COOKIE_SAME_SITE #{inspect(same_site)}, expected one of #{Enum.join(@same_site_values, ", ")}"
+  end
+
+  # Browsers drop SameSite=None cookies that aren't Secure
+  defp validate_same_site!(%{cookie_same_site: "None", cookie_secure: false}) do
+    raise ArgumentError, "COOKIE_SAME_SITE=None requires a secure (HTTPS) cookie"
+  end
+
+  defp validate_same_site!(_policy), do: :ok
+
+  defp validate_cookie_domain!(nil, _api_host), do: :ok
+  defp validate_cookie_domain!(_domain, nil), do: :ok
+
+  # Browsers ignore a cookie whose domain doesn't cover the host that sets it
+  defp validate_cookie_domain!(domain, api_host) do
+    domain = String.trim_leading(domain, ".")
+
+    unless api_host == domain or String.ends_with?(api_host, "." <> domain) do
+      raise ArgumentError, "COOKIE_DOMAIN #{inspect(domain)} does not cover the API host #{inspect(api_host)}"
+    end
+  end
+end
@@ -0,0 +1,43 @@
+defmodule ScribblBackendWeb.Plugs.AdminAuth do
+  @moduledoc """
+  Protects internal admin routes with a shared bearer token.
+
+  The token is configured through `ADMIN_API_TOKEN`. When it is not set the
+  admin API is disabled and every request is rejected.
+  """
+
+  import Plug.Conn
+  import Phoenix.Controller, only: [json: 2]
+
+  alias ScribblBackendWeb.ErrorCodes
+
+  def init(opts), do: opts
+
+  def call(conn, _opts) do
+    case Application.get_env(:scribbl_backend, :admin_api_token) do
+      token when is_binary(token) and token != "" ->
+        if valid_token?(conn, token) do
+          conn
+        else
+          reject(conn, :unauthorized, "Invalid admin token")
+        end
+
+      _ ->
+        reject(conn, :service_unavailable, "Admin API is not configured")
+    end
+  end
+
+  defp valid_token?(conn, token) do
+    case get_req_header(conn, "authorization") do
+      ["Bearer " <> provided] -> Plug.Crypto.secure_compare(provided, token)
+      _ -> false
+    end
+  end
+
+  defp reject(conn, status, message) do
+    conn
+    |> put_status(status)
+    |> json(%{error: message, code: ErrorCodes.code(status)})
+    |> halt()
+  end
+end
@@ -0,0 +1,55 @@
+defmodule ScribblBackendWeb.Plugs.ConcurrencyLimit do
+  @moduledoc """
+  Rejects HTTP requests with 429 when the client IP already has too many
+  requests in flight.
+
+  The limit is configured through `MAX_IN_FLIGHT_PER_IP` (default: 10).
+  IPs listed in `ScribblBackendWeb.RateLimitExemptions` are not limited.
+  Websocket connections are dispatched by the endpoint before plugs run,
+  so long-lived game sockets don't count against the limit.
+
+  The rest of the request runs inside this plug, passed as the `:plug`
+  option, so the slot is released when it completes even if a later plug
+  raises.
+  """
+
+  alias ScribblBackendWeb.ClientIP
+  alias ScribblBackendWeb.ConcurrencyLimiter
+  alias ScribblBackendWeb.RateLimited
+  alias ScribblBackendWeb.RateLimitExemptions
+
+  @default_max_in_flight 10
+  @retry_after_seconds 1
+
+  def init(opts) do
+    plug = Keyword.fetch!(opts, :plug)
+    {plug, plug.init(Keyword.get(opts, :plug_opts, []))}
+  end
+
+  def call(conn, {plug, plug_opts}) do
+    ip = ClientIP.get(conn)
+
+    if RateLimitExemptions.exempt?(ip) do
+      plug.call(conn, plug_opts)
+    else
+      limit(conn, ip, plug, plug_opts)
+    end
+  end
+
+  defp limit(conn, ip, plug, plug_opts) do
+    max_in_flight = Application.get_env(:scribbl_backend, :max_in_flight_per_ip, @default_max_in_flight)
+
+    case ConcurrencyLimiter.acquire(ip, max_in_flight) do
+      {:ok, ref} ->
+        try do
+          plug.call(conn, plug_opts)
+        after
+          ConcurrencyLimiter.release(ip, ref)
+        end
+
+      {:error, :limit_reached} ->
+        # A slot frees up as soon as one of the client's requests completes
+        RateLimited.reject(conn, "Too many concurrent requests", @retry_after_seconds)
+    end
+  end
+end
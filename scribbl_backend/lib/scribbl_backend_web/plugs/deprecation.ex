@@ -0,0 +1,37 @@
+defmodule ScribblBackendWeb.Plugs.Deprecation do
+  @moduledoc """
+  Announces deprecated endpoints registered in `ScribblBackendWeb.Deprecations`.
+
+  Adds `Deprecation`, `Sunset` and `Link` headers to matching responses, and a
+  `warning` field to JSON object responses so clients that don't inspect
+  headers still see it.
+  """
+
+  import Plug.Conn
+
+  alias ScribblBackendWeb.Deprecations
+
+  def init(opts), do: opts
+
+  def call(conn, _opts) do
+    case Deprecations.lookup(conn.method, conn.request_path) do
+      nil ->
+        conn
+
+      entry ->
+        conn
+        |> merge_resp_headers(Deprecations.headers(entry))
+        |> register_before_send(&add_warning(&1, Deprecations.warning(entry)))
+    end
+  end
+
+  defp add_warning(conn, warning) do
+    with [content_type | _] <- get_resp_header(conn, "content-type"),
+         true <- String.starts_with?(content_type, "application/json"),
+         {:ok, %{} = body} <- Jason.decode(IO.iodata_to_binary(conn.resp_body)) do
+      %{conn | resp_body: Jason.encode_to_iodata!(Map.put(body, "warning", warning))}
+    else
+      _ -> conn
+    end
+  end
+end
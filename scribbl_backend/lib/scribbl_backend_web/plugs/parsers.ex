@@ -0,0 +1,32 @@
+defmodule ScribblBackendWeb.Plugs.Parsers do
+  @moduledoc """
+  `Plug.Parsers` that counts bodies it can't parse in
+  `ScribblBackendWeb.RejectionMetrics` before the error is rendered as a
+  plain 4xx.
+
+  Takes the `Plug.Parsers` options plus the `:router` used to label the
+  endpoint.
+  """
+
+  alias ScribblBackendWeb.RejectionMetrics
+
+  def init(opts) do
+    {router, opts} = Keyword.pop!(opts, :router)
+    {router, Plug.Parsers.init(opts)}
+  end
+
+  def call(conn, {router, parsers}) do
+    Plug.Parsers.call(conn, parsers)
+  rescue
+    error in [Plug.Parsers.ParseError, Plug.Parsers.UnsupportedMediaTypeError, Plug.Parsers.RequestTooLargeError] ->
+      reason =
+        case error do
+          %Plug.Parsers.ParseError{} -> :malformed_body
+          %Plug.Parsers.UnsupportedMediaTypeError{} -> :unsupported_media_type
+          %Plug.Parsers.RequestTooLargeError{} -> :payload_too_large
+        end
+
+      RejectionMetrics.rejected(RejectionMetrics.endpoint_label(conn, router), reason)
+      reraise error, __STACKTRACE__
+  end
+end
@@ -0,0 +1,25 @@
+defmodule ScribblBackendWeb.Plugs.PublicAdmin do
+  @moduledoc """
+  Hides the admin API from the public listener once a dedicated internal
+  listener is configured through `INTERNAL_PORT`, so it's only reachable
+  from the internal network.
+  """
+
+  import Plug.Conn
+  import Phoenix.Controller, only: [json: 2]
+
+  alias ScribblBackendWeb.ErrorCodes
+
+  def init(opts), do: opts
+
+  def call(conn, _opts) do
+    if Application.get_env(:scribbl_backend, :internal_listener, false) do
+      conn
+      |> put_status(:not_found)
+      |> json(%{error: "Not Found", code: ErrorCodes.code(:not_found)})
+      |> halt()
+    else
+      conn
+    end
+  end
+end
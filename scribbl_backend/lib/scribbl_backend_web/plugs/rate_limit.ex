@@ -0,0 +1,77 @@
+defmodule ScribblBackendWeb.Plugs.RateLimit do
+  @moduledoc """
+  Limits requests according to the endpoint's policies in
+  `ScribblBackendWeb.RateLimitPolicies`.
+
+  Counters are kept in Redis so the limits hold across all backend nodes.
+  The client IP is resolved with `ScribblBackendWeb.ClientIP`, so clients
+  behind Caddy are limited individually. IPs listed in
+  `ScribblBackendWeb.RateLimitExemptions` are not limited.
+
+  Responses carry `X-RateLimit-Limit`, `X-RateLimit-Remaining` and
+  `X-RateLimit-Reset` (seconds) headers for the most restrictive policy,
+  so clients can throttle themselves. Rejected requests also get the exact
+  wait until every exceeded policy allows them again, in `Retry-After` and
+  `retry_after_seconds`.
+
+  Requests are allowed if Redis can't be reached, so a Redis outage doesn't
+  take the API down with it.
+
+  The `:limiter` option replaces `ScribblBackendWeb.RateLimiter`, e.g. with
+  a stub in tests.
+  """
+
+  import Plug.Conn
+
+  require Logger
+
+  alias ScribblBackendWeb.ClientIP
+  alias ScribblBackendWeb.RateLimited
+  alias ScribblBackendWeb.RateLimiter
+  alias ScribblBackendWeb.RateLimitExemptions
+  alias ScribblBackendWeb.RateLimitPolicies
+
+  def init(opts), do: Keyword.get(opts, :limiter, RateLimiter)
+
+  def call(conn, limiter) do
+    ip = ClientIP.get(conn)
+
+    if RateLimitExemptions.exempt?(ip) do
+      conn
+    else
+      policies = RateLimitPolicies.for_request(conn.method, conn.request_path)
+
+      limits =
+        Enum.map(policies, fn policy ->
+          %{
+            bucket: policy.name,
+            client: client_key(policy, ip),
+            limit: policy.limit,
+            window_seconds: policy.window_seconds
+          }
+        end)
+
+      case limiter.hit(limits) do
+        {:ok, infos} ->
+          put_headers(conn, infos)
+
+        {:error, :limit_reached, infos, retry_after} ->
+          conn
+          |> put_headers(infos)
+          |> RateLimited.reject("Too many requests", retry_after)
+
+        {:error, reason} ->
+          Logger.warning("[RateLimit] Redis unavailable, allowing request: #{inspect(reason)}")
+          conn
+      end
+    end
+  end
+
+  defp client_key(%{key: :ip}, ip), do: ClientIP.format(ip)
+  defp client_key(%{key: :global}, _ip), do: "all"
+
+  # Report the policy closest to its limit
+  defp put_headers(conn, infos) do
+    merge_resp_headers(conn, RateLimiter.headers(Enum.min_by(infos, & &1.remaining)))
+  end
+end
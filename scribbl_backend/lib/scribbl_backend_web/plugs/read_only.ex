@@ -0,0 +1,34 @@
+defmodule ScribblBackendWeb.Plugs.ReadOnly do
+  @moduledoc """
+  Rejects mutating requests with 503 `READ_ONLY` while
+  `ScribblBackend.ReadOnlyMode` is enabled.
+  """
+
+  import Plug.Conn
+  import Phoenix.Controller, only: [json: 2]
+
+  alias ScribblBackend.ReadOnlyMode
+  alias ScribblBackendWeb.ErrorCodes
+
+  @mutating_methods ~w(POST PUT PATCH DELETE)
+
+  # Renders an image from the request body without touching any state
+  @safe_paths ["/api/images/game-over"]
+
+  def init(opts), do: opts
+
+  def call(conn, _opts) do
+    if ReadOnlyMode.enabled?() and conn.method in @mutating_methods and
+         conn.request_path not in @safe_paths do
+      conn
+      |> put_status(:service_unavailable)
+      |> json(%{
+        error: "Scribbl is in read-only mode for maintenance",
+        code: ErrorCodes.code(:read_only)
+      })
+      |> halt()
+    else
+      conn
+    end
+  end
+end
@@ -0,0 +1,48 @@
+defmodule ScribblBackendWeb.PublicStatus do
+  @moduledoc """
+  Builds the coarse, public service status served at `GET /status`, for
+  embedding in a status page.
+
+  Internal dependencies are mapped onto user-facing components and
+  statuses (`operational`, `degraded`, `outage`, `maintenance`), so no
+  dependency names, errors or node details are exposed.
+  """
+
+  # User-facing components and the dependencies they rely on
+  @components %{
+    api: [:redis],
+    game_rooms: [:redis]
+  }
+
+  @severity %{"operational" => 0, "maintenance" => 1, "degraded" => 2, "outage" => 3}
+
+  @doc """
+  Build the public status.
+
+  ## Parameters
+    - `health`: Dependency health as returned by `ScribblBackend.HealthMonitor.all/0`.
+    - `read_only?`: Whether read-only mode is enabled.
+
+  ## Returns
+    `%{status: overall, components: %{component => status}}`.
+  """
+  def build(health, read_only?) do
+    components =
+      Map.new(@components, fn {component, dependencies} ->
+        {component, component_status(component, dependencies, health, read_only?)}
+      end)
+
+    %{status: components |> Map.values() |> Enum.max_by(&@severity[&1]), components: components}
+  end
+
+  defp component_status(component, dependencies, health, read_only?) do
+    statuses = Enum.map(dependencies, &get_in(health, [&1, :status]))
+
+    cond do
+      :unhealthy in statuses -> "outage"
+      :degraded in statuses -> "degraded"
+      read_only? and component == :game_rooms -> "maintenance"
+      true -> "operational"
+    end
+  end
+end
@@ -0,0 +1,165 @@
+defmodule ScribblBackendWeb.RateLimitExemptions do
+  @moduledoc """
+  Client IPs that bypass request limiting, such as office networks or
+  load-test runners.
+
+  Exemptions come from two sources:
+    - `RATE_LIMIT_EXEMPT_IPS`: a comma-separated list of CIDRs fixed at boot.
+    - A Redis set managed at runtime through the `/admin/rate-limit/exemptions` API.
+
+  `exempt?/1` runs on every HTTP request, so the parsed exemptions are kept
+  in memory and never read from Redis on the request path. Admin changes are
+  broadcast over PubSub so every node reloads the Redis set, and each node
+  also reloads it periodically in case it missed a broadcast.
+  """
+
+  use GenServer
+  require Logger
+
+  alias ScribblBackend.KeyManager
+  alias ScribblBackend.PersistentTerm
+  alias ScribblBackend.RedisHelper
+  alias ScribblBackendWeb.ClientIP
+
+  @topic "admin:rate_limit_exemptions"
+  @refresh_interval_ms 60_000
+
+  ## Client API
+
+  def start_link(_opts) do
+    GenServer.start_link(__MODULE__, %{}, name: __MODULE__)
+  end
+
+  @doc """
+  Check whether a client IP is exempt from request limiting. Until the Redis
+  set has been loaded only `RATE_LIMIT_EXEMPT_IPS` applies.
+
+  ## Parameters
+    - `ip`: The client IP address tuple.
+  """
+  def exempt?(ip) do
+    ClientIP.trusted?(ip, :persistent_term.get(__MODULE__, []))
+  end
+
+  @doc """
+  List the exemptions managed in Redis.
+
+  ## Returns
+    `{:ok, entries}` or `{:error, reason}`.
+  """
+  def list do
+    RedisHelper.smembers(KeyManager.rate_limit_exemptions())
+  end
+
+  @doc """
+  Add a CIDR or single address to the Redis-managed exemptions.
+
+  ## Parameters
+    - `entry`: A CIDR such as `"203.0.113.0/24"` or an address such as `"203.0.113.7"`.
+
+  ## Returns
+    `:ok`, `{:error, reason}` if the entry is invalid, or
+    `{:error, :unavailable}` if Redis could not be updated.
+  """
+  def add(entry) when is_binary(entry) do
+    entry = String.trim(entry)
+
+    case ClientIP.parse_cidr(entry) do
+      {:ok, _cidr} ->
+        KeyManager.rate_limit_exemptions()
+        |> RedisHelper.sadd(entry)
+        |> broadcast_change()
+
+      :error ->
+        {:error, "Invalid IP address or CIDR"}
+    end
+  end
+
+  def add(_entry), do: {:error, "Invalid IP address or CIDR"}
+
+  @doc """
+  Remove an entry from the Redis-managed exemptions.
+
+  ## Parameters
+    - `entry`: The entry exactly as it was added.
+
+  ## Returns
+    `:ok` or `{:error, :unavailable}` if Redis could not be updated.
+  """
+  def remove(entry) when is_binary(entry) do
+    KeyManager.rate_limit_exemptions()
+    |> RedisHelper.srem(String.trim(entry))
+    |> broadcast_change()
+  end
+
+  # Redis already has the change, so a node that misses the broadcast
+  # still picks it up on its next periodic reload
+  defp broadcast_change({:ok, _count}) do
+    Phoenix.PubSub.broadcast(ScribblBackend.PubSub, @topic, :reload)
+    :ok
+  end
+
+  defp broadcast_change({:error, _reason}), do: {:error, :unavailable}
+
+  ## Server Callbacks
+
+  @impl true
+  def init(state) do
+    Phoenix.PubSub.subscribe(ScribblBackend.PubSub, @topic)
+    :persistent_term.put(__MODULE__, configured())
+    {:ok, state, {:continue, :reload}}
+  end
+
+  @impl true
+  def handle_continue(:reload, state) do
+    reload()
+    schedule_refresh()
+    {:noreply, state}
+  end
+
+  @impl true
+  def handle_info(:reload, state) do
+    reload()
+    {:noreply, state}
+  end
+
+  def handle_info(:refresh, state) do
+    reload()
+    schedule_refresh()
+    {:noreply, state}
+  end
+
+  def handle_info(_msg, state) do
+    {:noreply, state}
+  end
+
+  defp reload do
+    case list() do
+      {:ok, entries} ->
+        PersistentTerm.put_if_changed(__MODULE__, configured() ++ parse_all(entries))
+
+      {:error, reason} ->
+        # Keep the last known exemptions until Redis is back
+        Logger.warning("[RateLimitExemptions] Failed to load exemptions: #{inspect(reason)}")
+    end
+  end
+
+  defp schedule_refresh do
+    Process.send_after(self(), :refresh, @refresh_interval_ms)
+  end
+
+  defp configured do
+    :scribbl_backend
+    |> Application.get_env(:rate_limit_exempt_ips, [])
+    |> parse_all()
+  end
+
+  defp parse_all(entries) do
+    Enum.flat_map(entries, fn entry ->
+      case ClientIP.parse_cidr(entry) do
+        {:ok, cidr} -> [cidr]
+        :error -> []
+      end
+    end)
+  end
+end
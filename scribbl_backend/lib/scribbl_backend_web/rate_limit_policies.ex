@@ -0,0 +1,102 @@
+defmodule ScribblBackendWeb.RateLimitPolicies do
+  @moduledoc """
+  Registry of per-endpoint rate limit policies used by
+  `ScribblBackendWeb.Plugs.RateLimit`.
+
+  Each policy names an endpoint, its limit per window, and how requests are
+  keyed:
+    - `:ip`: one counter per client IP.
+    - `:global`: one counter shared by all clients, for endpoints that are
+      expensive regardless of who calls them.
+
+  Requests that match no policy use the `"api"` policy, whose limit is
+  `RATE_LIMIT_PER_MINUTE`. Limits can be changed without code changes through
+  `RATE_LIMIT_POLICIES`, a JSON object keyed by policy name, e.g.
+  `{"game_over_image": {"limit": 5, "window_seconds": 60}}`.
+  """
+
+  @policies [
+    %{name: "join_random", method: "GET", path: "/api/rooms/join-random", limit: 30, window_seconds: 60, key: :ip},
+    %{name: "generate_id", method: "GET", path: "/api/rooms/generate-id", limit: 30, window_seconds: 60, key: :ip},
+    %{name: "game_over_image", method: "POST", path: "/api/images/game-over", limit: 10, window_seconds: 60, key: :ip},
+    # Image rendering is CPU heavy, so it is also capped across all clients
+    %{name: "game_over_image_global", method: "POST", path: "/api/images/game-over", limit: 300, window_seconds: 60, key: :global}
+  ]
+
+  @default_limit 60
+
+  @doc """
+  Get the policies that apply to a request, falling back to the `"api"` policy.
+
+  ## Parameters
+    - `method`: The HTTP method, e.g. `"GET"`.
+    - `path`: The request path.
+  """
+  def for_request(method, path) do
+    case Enum.filter(policies(), &(&1.method == method and &1.path == path)) do
+      [] -> [default_policy()]
+      matching -> matching
+    end
+  end
+
+  @doc """
+  Get all endpoint policies with configured overrides applied.
+  """
+  def policies do
+    overrides = Application.get_env(:scribbl_backend, :rate_limit_policies, %{})
+
+    Enum.map(@policies, fn policy ->
+      Map.merge(policy, Map.get(overrides, policy.name, %{}))
+    end)
+  end
+
+  @doc """
+  Parse and validate the `RATE_LIMIT_POLICIES` JSON. Raises on unknown policy
+  names or invalid limits so mistakes are caught at startup.
+
+  ## Returns
+    A map of policy name to `%{limit: integer, window_seconds: integer}` overrides.
+  """
+  def parse_overrides!(json) do
+    known = ["api" | Enum.map(@policies, & &1.name)]
+
+    json
+    |> Jason.decode!()
+    |> Map.new(fn {name, settings} ->
+      unless name in known do
+        raise ArgumentError, "unknown rate limit policy in RATE_LIMIT_POLICIES: #{inspect(name)}"
+      end
+
+      {name, parse_settings!(name, settings)}
+    end)
+  end
+
+  defp default_policy do
+    overrides = Application.get_env(:scribbl_backend, :rate_limit_policies, %{})
+
+    Map.merge(
+      %{
+        name: "api",
+        limit: Application.get_env(:scribbl_backend, :rate_limit_per_minute, @default_limit),
+        window_seconds: 60,
+        key: :ip
+      },
+      Map.get(overrides, "api", %{})
+    )
+  end
+
+  defp parse_settings!(name, settings) when is_map(settings) do
+    Map.new(settings, fn
+      {field, value} when field in ["limit", "window_seconds"] and is_integer(value) and value > 0 ->
+        {String.to_existing_atom(field), value}
+
+      {field, value} ->
+        raise ArgumentError,
+              "invalid #{field} for rate limit policy #{inspect(name)}: #{inspect(value)}"
+    end)
+  end
+
+  defp parse_settings!(name, _settings) do
+    raise ArgumentError, "rate limit policy #{inspect(name)} must be a JSON object"
+  end
+end
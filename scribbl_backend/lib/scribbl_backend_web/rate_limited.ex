@@ -0,0 +1,34 @@
+defmodule ScribblBackendWeb.RateLimited do
+  @moduledoc """
+  Builds the 429 response shared by the request limiters, so every limiter
+  tells clients how long to wait in the same way: a standard `Retry-After`
+  header and a `retry_after_seconds` field in the JSON body.
+  """
+
+  import Plug.Conn
+  import Phoenix.Controller, only: [json: 2]
+
+  alias ScribblBackendWeb.ErrorCodes
+
+  @doc """
+  Reject a request with 429 and halt the connection.
+
+  ## Parameters
+    - `conn`: The Plug connection.
+    - `message`: The error message.
+    - `retry_after_seconds`: Seconds until the client may retry, at least 1.
+  """
+  def reject(conn, message, retry_after_seconds) do
+    retry_after_seconds = max(retry_after_seconds, 1)
+
+    conn
+    |> put_resp_header("retry-after", Integer.to_string(retry_after_seconds))
+    |> put_status(:too_many_requests)
+    |> json(%{
+      error: message,
+      code: ErrorCodes.code(:rate_limited),
+      retry_after_seconds: retry_after_seconds
+    })
+    |> halt()
+  end
+end
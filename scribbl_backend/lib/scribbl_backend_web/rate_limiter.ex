@@ -0,0 +1,152 @@
+defmodule ScribblBackendWeb.RateLimiter do
+  @moduledoc """
+  Sliding-window request counters in Redis, shared by all backend nodes.
+
+  Each client's requests are kept in a sorted set scored by timestamp, and a
+  Lua script trims, counts and records a request atomically. Unlike a fixed
+  INCR+EXPIRE window this can't leave a counter without an expiry, and it
+  doesn't allow a double burst around the window boundary.
+
+  A request can be counted against several limits at once. It is recorded
+  in all of them only if every limit allows it, so a rejected request never
+  uses up quota and the reported retry time stays exact.
+  """
+
+  alias ScribblBackend.KeyManager
+  alias ScribblBackend.RedisHelper
+
+  # KEYS: one counter key per limit
+  # ARGV: now (ms), unique member for this request, then window (ms) and limit per key
+  # Returns {allowed (1/0), retry after (ms), then remaining and reset (ms) per key}
+  @script """
+  local now = tonumber(ARGV[1])
+  local counts = {}
+  local allowed = 1
+  local retry_after = 0
+
+  for i, key in ipairs(KEYS) do
+    local window = tonumber(ARGV[i * 2 + 1])
+    local limit = tonumber(ARGV[i * 2 + 2])
+
+    redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+    counts[i] = redis.call('ZCARD', key)
+
+    if counts[i] >= limit then
+      allowed = 0
+
+      -- The request can succeed once every exceeded limit has a free slot
+      local oldest = redis.call('ZRANGE', key, counts[i] - limit, counts[i] - limit, 'WITHSCORES')
+      local wait = window
+      if oldest[2] ~= nil then
+        wait = tonumber(oldest[2]) + window - now
+      end
+      retry_after = math.max(retry_after, wait)
+    end
+  end
+
+  local result = {allowed, retry_after}
+
+  for i, key in ipairs(KEYS) do
+    local window = tonumber(ARGV[i * 2 + 1])
+    local limit = tonumber(ARGV[i * 2 + 2])
+
+    if allowed == 1 then
+      redis.call('ZADD', key, now, ARGV[2])
+      redis.call('PEXPIRE', key, window)
+      counts[i] = counts[i] + 1
+    end
+
+    local reset = window
+    local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+    if oldest[2] ~= nil then
+      reset = tonumber(oldest[2]) + window - now
+    end
+
+    table.insert(result, math.max(limit - counts[i], 0))
+    table.insert(result, reset)
+  end
+
+  return result
+  """
+
+  @script_sha :crypto.hash(:sha, @script) |> Base.encode16(case: :lower)
+
+  @doc """
+  Count a request against one or more limits.
+
+  ## Parameters
+    - `limits`: A list of maps with the `bucket` name (e.g. `"api"`), the
+      `client` identifier (e.g. a formatted IP address), the `limit` and the
+      `window_seconds`.
+
+  ## Returns
+    - `{:ok, infos}` if every limit allows the request.
+    - `{:error, :limit_reached, infos, retry_after_seconds}` if any limit is
+      exceeded. `retry_after_seconds` is the earliest time at which every
+      exceeded limit allows the request again.
+    - `{:error, reason}` if Redis could not be queried.
+
+    `infos` has one map per limit, in order, with the `limit`, the
+    `remaining` requests in the window, and `reset_seconds` until the oldest
+    counted request leaves the window.
+  """
+  def hit(limits) do
+    keys = Enum.map(limits, &KeyManager.rate_limit(&1.bucket, &1.client))
+
+    args =
+      [System.system_time(:millisecond), Base.encode16(:crypto.strong_rand_bytes(8))] ++
+        Enum.flat_map(limits, &[&1.window_seconds * 1000, &1.limit])
+
+    case eval(keys, Enum.map(args, &to_string/1)) do
+      {:ok, [allowed, retry_after_ms | counters]} ->
+        infos =
+          counters
+          |> Enum.chunk_every(2)
+          |> Enum.zip_with(limits, fn [remaining, reset_ms], %{limit: limit} ->
+            %{limit: limit, remaining: remaining, reset_seconds: to_seconds(reset_ms)}
+          end)
+
+        if allowed == 1,
+          do: {:ok, infos},
+          else: {:error, :limit_reached, infos, to_seconds(retry_after_ms)}
+
+      {:error, reason} ->
+        {:error, reason}
+    end
+  end
+
+  @doc """
+  Build the `X-RateLimit-*` response headers for a counted request.
+
+  ## Parameters
+    - `info`: One of the info maps returned by `hit/1`.
+
+  ## Returns
+    A list of `{name, value}` header tuples. `X-RateLimit-Reset` is in
+    seconds from now.
+  """
+  def headers(%{limit: limit, remaining: remaining, reset_seconds: reset_seconds}) do
+    [
+      {"x-ratelimit-limit", Integer.to_string(limit)},
+      {"x-ratelimit-remaining", Integer.to_string(remaining)},
+      {"x-ratelimit-reset", Integer.to_string(reset_seconds)}
+    ]
+  end
+
+  # Round up, so a client that waits the advertised time is never rejected
+  defp to_seconds(ms), do: max(div(ms + 999, 1000), 1)
+
+  # Run the cached script, loading it on first use on each Redis server
+  defp eval(keys, args) do
+    conn = RedisHelper.redix_conn()
+    numkeys = Integer.to_string(length(keys))
+
+    case Redix.command(conn, ["EVALSHA", @script_sha, numkeys] ++ keys ++ args) do
+      {:error, %Redix.Error{message: "NOSCRIPT" <> _}} ->
+        Redix.command(conn, ["EVAL", @script, numkeys] ++ keys ++ args)
+
+      result ->
+        result
+    end
+  end
+end
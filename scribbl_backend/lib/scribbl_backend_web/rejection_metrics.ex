@@ -0,0 +1,92 @@
+defmodule ScribblBackendWeb.RejectionMetrics do
+  @moduledoc """
+  Counts requests rejected for malformed or invalid input, segmented by
+  endpoint and reason, so client integration bugs show up in dashboards
+  instead of only as anonymous 400s in access logs.
+
+  Each rejection emits `[:scribbl_backend, :request, :rejected]` with a
+  `count` measurement and `endpoint` and `reason` metadata, and is added to
+  per-node totals in ETS that `/admin/metrics` exports in the OpenMetrics
+  format.
+  """
+
+  use GenServer
+
+  alias ScribblBackendWeb.OpenMetrics
+
+  @table :scribbl_rejected_requests
+
+  ## Client API
+
+  def start_link(_opts) do
+    GenServer.start_link(__MODULE__, %{}, name: __MODULE__)
+  end
+
+  @doc """
+  Record a rejected request.
+
+  ## Parameters
+    - `endpoint`: A low-cardinality endpoint label, e.g. `"PUT /admin/loglevel"`.
+    - `reason`: The failure reason, e.g. `:malformed_body`.
+  """
+  def rejected(endpoint, reason) do
+    :telemetry.execute(
+      [:scribbl_backend, :request, :rejected],
+      %{count: 1},
+      %{endpoint: endpoint, reason: reason}
+    )
+
+    key = {endpoint, reason}
+    :ets.update_counter(@table, key, 1, {key, 0})
+  end
+
+  @doc """
+  Get the number of rejections per `{endpoint, reason}` since the node started.
+  """
+  def counts do
+    @table |> :ets.tab2list() |> Map.new()
+  end
+
+  @doc """
+  Render the rejection counts as an OpenMetrics counter family, without the
+  `# EOF` terminator.
+
+  ## Parameters
+    - `counts`: The map returned by `counts/0`.
+  """
+  def families(counts) do
+    samples =
+      for {{endpoint, reason}, count} <- Enum.sort(counts) do
+        OpenMetrics.sample("scribbl_request_rejected_total", [endpoint: endpoint, reason: reason], count)
+      end
+
+    [
+      "# TYPE scribbl_request_rejected counter\n",
+      "# HELP scribbl_request_rejected Requests rejected for malformed or invalid input since the node started.\n",
+      samples
+    ]
+  end
+
+  @doc """
+  Get the endpoint label for a connection that may not have been routed yet,
+  using the route pattern so arbitrary paths don't create new series.
+
+  ## Parameters
+    - `conn`: The Plug connection.
+    - `router`: The router that serves the connection.
+  """
+  def endpoint_label(conn, router) do
+    case Phoenix.Router.route_info(router, conn.method, conn.request_path, conn.host) do
+      %{route: route} -> "#{conn.method} #{route}"
+      :error -> "unmatched"
+    end
+  end
+
+  ## Server Callbacks
+
+  @impl true
+  def init(state) do
+    :ets.new(@table, [:set, :public, :named_table, write_concurrency: true])
+    {:ok, state}
+  end
+end
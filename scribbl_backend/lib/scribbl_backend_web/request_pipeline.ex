@@ -0,0 +1,31 @@
+defmodule ScribblBackendWeb.RequestPipeline do
+  @moduledoc """
+  The part of the endpoint pipeline that runs once a request holds a
+  concurrency slot: body parsing, sessions and routing.
+
+  It is a separate plug so `ScribblBackendWeb.Plugs.ConcurrencyLimit` can
+  run it inside `try/after`. Plugs here may raise (e.g. on a malformed body
+  or an unknown route), and the error page is then rendered from the conn
+  the endpoint started with, so `before_send` callbacks registered along the
+  way never fire.
+  """
+
+  use Plug.Builder, init_mode: Phoenix.plug_init_mode()
+
+  plug ScribblBackendWeb.Plugs.Parsers,
+    parsers: [:urlencoded, :multipart, :json],
+    pass: ["*/*"],
+    json_decoder: Phoenix.json_library(),
+    router: ScribblBackendWeb.Router
+
+  plug Plug.MethodOverride
+  plug Plug.Head
+  plug :session
+  plug ScribblBackendWeb.Router
+
+  # The origin policy is runtime config, so the session plug can't be
+  # initialized at compile time
+  defp session(conn, _opts) do
+    Plug.Session.call(conn, Plug.Session.init(ScribblBackendWeb.Endpoint.session_options()))
+  end
+end
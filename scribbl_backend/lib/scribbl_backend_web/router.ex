@@ -3,10 +3,23 @@ defmodule ScribblBackendWeb.Router do
 
   pipeline :api do
     plug :accepts, ["json"]
+    plug ScribblBackendWeb.Plugs.Deprecation
+  end
+
+  pipeline :rate_limited do
+    plug ScribblBackendWeb.Plugs.RateLimit
+  end
+
+  pipeline :read_only do
+    plug ScribblBackendWeb.Plugs.ReadOnly
+  end
+
+  pipeline :public_admin do
+    plug ScribblBackendWeb.Plugs.PublicAdmin
   end
 
       scope "/api", ScribblBackendWeb do
-    pipe_through :api
+    pipe_through [:api, :rate_limited, :read_only]
 
     # Room management endpoints
     get "/rooms/join-random", RoomController, :join_random
@@ -16,6 +29,23 @@ defmodule ScribblBackendWeb.Router do
     post "/images/game-over", ImageController, :generate_game_over_image
   end
 
+  # Public service status for status pages. Not rate limited: it must stay
+  # fast when Redis is down, and it only reads cached health, so the
+  # cache-control header and the in-flight cap are enough
+  scope "/", ScribblBackendWeb do
+    pipe_through :api
+
+    get "/status", StatusController, :show
+  end
+
+  # Internal admin endpoints, see AdminRouter. Moved to the internal
+  # listener when INTERNAL_PORT is set
+  scope "/" do
+    pipe_through :public_admin
+
+    forward "/admin", ScribblBackendWeb.AdminRouter
+  end
+
   # Enable LiveDashboard in development
   if Application.compile_env(:scribbl_backend, :dev_routes) do
     # If you want to use the LiveDashboard in production, you should put
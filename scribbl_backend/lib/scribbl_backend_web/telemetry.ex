@@ -52,6 +52,16 @@ defmodule ScribblBackendWeb.Telemetry do
         unit: {:native, :millisecond}
       ),
 
+      # Request rejection Metrics
+      counter("scribbl_backend.request.rejected.count",
+        tags: [:endpoint, :reason]
+      ),
+
+      # Health Metrics
+      counter("scribbl_backend.health.transition.count",
+        tags: [:dependency, :from, :to]
+      ),
+
       # VM Metrics
       summary("vm.memory.total", unit: {:byte, :kilobyte}),
       summary("vm.total_run_queue_lengths.total"),
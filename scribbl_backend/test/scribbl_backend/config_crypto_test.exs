@@ -0,0 +1,59 @@
+defmodule ScribblBackend.ConfigCryptoTest do
+  # Not async: the tests change the CONFIG_MASTER_KEY environment variable
+  use ExUnit.Case, async: false
+
+  alias ScribblBackend.ConfigCrypto
+
+  setup do
+    key = ConfigCrypto.generate_key()
+    System.put_env("CONFIG_MASTER_KEY", key)
+    on_exit(fn ->
+      System.delete_env("CONFIG_MASTER_KEY")
+      System.delete_env("CONFIG_MASTER_KEY_FILE")
+    end)
+    {:ok, key: key}
+  end
+
+  test "round-trips an encrypted value", %{key: key} do
+    encrypted = ConfigCrypto.encrypt("s3cret", key)
+
+    assert "enc:v1:" <> _ = encrypted
+    assert ConfigCrypto.decrypt(encrypted) == {:ok, "s3cret"}
+  end
+
+  test "passes plaintext values through" do
+    assert ConfigCrypto.decrypt("plain") == {:ok, "plain"}
+  end
+
+  test "rejects values encrypted with another key" do
+    encrypted = ConfigCrypto.encrypt("s3cret", ConfigCrypto.generate_key())
+
+    assert {:error, _reason} = ConfigCrypto.decrypt(encrypted)
+  end
+
+  test "treats an empty CONFIG_MASTER_KEY_FILE as unset", %{key: key} do
+    System.put_env("CONFIG_MASTER_KEY_FILE", "")
+
+    assert ConfigCrypto.decrypt(ConfigCrypto.encrypt("s3cret", key)) == {:ok, "s3cret"}
+  end
+
+  test "reports a missing master key when both variables are empty", %{key: key} do
+    encrypted = ConfigCrypto.encrypt("s3cret", key)
+    System.put_env("CONFIG_MASTER_KEY", "")
+    System.put_env("CONFIG_MASTER_KEY_FILE", "")
+
+    assert ConfigCrypto.decrypt(encrypted) ==
+             {:error, "CONFIG_MASTER_KEY or CONFIG_MASTER_KEY_FILE must be set"}
+  end
+
+  test "get_env returns the default for empty variables" do
+    System.put_env("SCRIBBL_TEST_SECRET", "")
+    on_exit(fn -> System.delete_env("SCRIBBL_TEST_SECRET") end)
+
+    assert ConfigCrypto.get_env("SCRIBBL_TEST_SECRET", "default") == "default"
+  end
+
+  test "rejects unknown encryption versions" do
+    assert ConfigCrypto.decrypt("enc:v2:abc") == {:error, "unsupported encryption version"}
+  end
+end
@@ -0,0 +1,49 @@
+defmodule ScribblBackend.LogLevelTest do
+  # Not async: changes the node-wide Logger level
+  use ExUnit.Case, async: false
+
+  alias ScribblBackend.LogLevel
+
+  setup do
+    %{base_level: base_level} = LogLevel.current()
+    on_exit(fn -> Logger.configure(level: base_level) end)
+    {:ok, base_level: base_level}
+  end
+
+  # Changes are applied by the GenServer after a broadcast
+  defp wait_for_change, do: :sys.get_state(LogLevel)
+
+  defp wait_for_level(level, attempts \\ 50) do
+    cond do
+      Logger.level() == level -> :ok
+      attempts == 0 -> flunk("log level is #{Logger.level()}, expected #{level}")
+      true ->
+        Process.sleep(10)
+        wait_for_level(level, attempts - 1)
+    end
+  end
+
+  test "reverts a temporary level after its duration", %{base_level: base_level} do
+    assert {:ok, %{level: "error"}} = LogLevel.set_level("error", 200)
+    wait_for_change()
+    assert Logger.level() == :error
+
+    wait_for_level(base_level)
+    assert LogLevel.current() == %{level: base_level, base_level: base_level}
+  end
+
+  test "caps the duration at one hour", %{base_level: base_level} do
+    {:ok, %{revert_at: revert_at}} = LogLevel.set_level("error", :timer.hours(5))
+
+    assert DateTime.diff(revert_at, DateTime.utc_now()) in 3595..3600
+
+    # Replace the pending hour-long revert with a short one
+    LogLevel.set_level("error", 10)
+    wait_for_level(base_level)
+  end
+
+  test "rejects invalid levels and durations" do
+    assert {:error, "Level must be one of: " <> _levels} = LogLevel.set_level("verbose", 1000)
+    assert {:error, "Duration must be a positive number of seconds"} = LogLevel.set_level("debug", 0)
+  end
+end
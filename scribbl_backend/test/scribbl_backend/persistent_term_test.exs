@@ -0,0 +1,18 @@
+defmodule ScribblBackend.PersistentTermTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackend.PersistentTerm
+
+  setup do
+    key = {__MODULE__, make_ref()}
+    on_exit(fn -> :persistent_term.erase(key) end)
+    %{key: key}
+  end
+
+  test "stores new and changed values only", %{key: key} do
+    assert PersistentTerm.put_if_changed(key, false)
+    refute PersistentTerm.put_if_changed(key, false)
+    assert PersistentTerm.put_if_changed(key, true)
+    assert :persistent_term.get(key) == true
+  end
+end
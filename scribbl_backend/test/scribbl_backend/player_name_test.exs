@@ -0,0 +1,37 @@
+defmodule ScribblBackend.PlayerNameTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackend.PlayerName
+
+  test "counts graphemes rather than bytes" do
+    # 34 bytes, but well under 20 graphemes
+    assert {:ok, "नमस्ते दोस्त"} = PlayerName.validate("नमस्ते दोस्त")
+    assert {:ok, _name} = PlayerName.validate(String.duplicate("👩‍👩‍👧", 20))
+    assert {:error, _reason} = PlayerName.validate(String.duplicate("👩‍👩‍👧", 21))
+  end
+
+  test "strips bidi controls and zero-width characters but keeps joiners" do
+    assert {:ok, "evil"} = PlayerName.validate("\u202Eevil\u200B")
+    assert {:ok, "क्\u200Dष"} = PlayerName.validate("क्\u200Dष")
+  end
+
+  test "accepts emoji presentation and subdivision flags" do
+    assert {:ok, "\u2764\uFE0F"} = PlayerName.validate("\u2764\uFE0F")
+
+    scotland = "\u{1F3F4}\u{E0067}\u{E0062}\u{E0073}\u{E0063}\u{E0074}\u{E007F}"
+    assert {:ok, "Ana " <> ^scotland} = PlayerName.validate("Ana " <> scotland)
+  end
+
+  test "collapses whitespace and NFC-normalizes" do
+    assert {:ok, "Zo\u00EB Q"} = PlayerName.validate("  Zoe\u0308 \t\n Q ")
+  end
+
+  test "rejects empty, control-only and unsupported names" do
+    assert {:error, _reason} = PlayerName.validate("   ")
+    assert {:error, _reason} = PlayerName.validate("\u200B\u200F")
+    assert {:error, _reason} = PlayerName.validate("private\uE000use")
+    assert {:error, _reason} = PlayerName.validate("z" <> String.duplicate("\u0301", 30))
+    assert {:error, _reason} = PlayerName.validate(<<0xFF>>)
+    assert {:error, _reason} = PlayerName.validate(nil)
+  end
+end
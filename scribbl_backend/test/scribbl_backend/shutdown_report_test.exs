@@ -0,0 +1,24 @@
+defmodule ScribblBackend.ShutdownReportTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackend.ShutdownReport
+
+  defp redis_up, do: true
+  defp redis_down, do: false
+
+  test "a requested shutdown that drained every request is clean" do
+    assert ShutdownReport.kind(true, 0, &redis_down/0) == :clean
+  end
+
+  test "a requested shutdown that dropped requests is forced" do
+    assert ShutdownReport.kind(true, 3, &redis_up/0) == :forced
+  end
+
+  test "an unrequested shutdown is a crash while Redis is reachable" do
+    assert ShutdownReport.kind(false, 0, &redis_up/0) == :crashed
+  end
+
+  test "an unrequested shutdown is a dependency failure while Redis is down" do
+    assert ShutdownReport.kind(false, 0, &redis_down/0) == :dependency_failure
+  end
+end
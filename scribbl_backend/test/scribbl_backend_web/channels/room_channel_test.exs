@@ -0,0 +1,34 @@
+defmodule ScribblBackendWeb.RoomChannelTest do
+  # Not async: read-only mode is node-wide
+  use ExUnit.Case, async: false
+
+  alias ScribblBackend.ReadOnlyMode
+  alias ScribblBackendWeb.RoomChannel
+
+  setup do
+    set_read_only(true)
+    on_exit(fn -> set_read_only(false) end)
+
+    topic = "room:test-#{System.unique_integer([:positive])}"
+    {:ok, socket: %Phoenix.Socket{topic: topic, assigns: %{user_id: "player-1"}}}
+  end
+
+  defp set_read_only(enabled) do
+    ReadOnlyMode.set(enabled)
+    :sys.get_state(ReadOnlyMode)
+  end
+
+  test "refuses mutating game events in read-only mode", %{socket: socket} do
+    for event <- ["start_game", "drawing_clear", "new_message"] do
+      assert {:reply, {:error, %{code: "READ_ONLY"}}, ^socket} =
+               RoomChannel.handle_in(event, %{"message" => "hi"}, socket)
+    end
+  end
+
+  test "still relays events that don't write state", %{socket: socket} do
+    Phoenix.PubSub.subscribe(ScribblBackend.PubSub, socket.topic)
+
+    assert {:noreply, ^socket} = RoomChannel.handle_in("like_drawing", %{}, socket)
+    assert_receive %{event: "drawing_liked", payload: %{"user_id" => "player-1"}}
+  end
+end
@@ -0,0 +1,41 @@
+defmodule ScribblBackendWeb.ClientIPTest do
+  use ScribblBackendWeb.ConnCase, async: true
+
+  alias ScribblBackendWeb.ClientIP
+
+  test "uses the remote address when the peer is not a trusted proxy", %{conn: conn} do
+    conn =
+      %{conn | remote_ip: {203, 0, 113, 7}}
+      |> put_req_header("x-forwarded-for", "198.51.100.1")
+
+    assert ClientIP.get(conn) == {203, 0, 113, 7}
+  end
+
+  test "uses the first untrusted X-Forwarded-For hop behind a trusted proxy", %{conn: conn} do
+    conn =
+      %{conn | remote_ip: {172, 18, 0, 5}}
+      |> put_req_header("x-forwarded-for", "198.51.100.1, 203.0.113.9, 10.0.0.2")
+
+    assert ClientIP.get(conn) == {203, 0, 113, 9}
+  end
+
+  test "unwraps IPv4-mapped IPv6 addresses", %{conn: conn} do
+    conn = %{conn | remote_ip: {0, 0, 0, 0, 0, 0xFFFF, 0xCB00, 0x7107}}
+
+    assert ClientIP.get(conn) == {203, 0, 113, 7}
+  end
+
+  test "treats Unix socket peers as local", %{conn: conn} do
+    conn = %{conn | remote_ip: {:local, "/run/scribbl/api.sock"}}
+
+    assert ClientIP.get(conn) == {127, 0, 0, 1}
+  end
+
+  test "parses CIDRs" do
+    assert {:ok, cidr} = ClientIP.parse_cidr("10.0.0.0/8")
+    assert ClientIP.trusted?({10, 1, 2, 3}, [cidr])
+    refute ClientIP.trusted?({11, 0, 0, 1}, [cidr])
+    assert ClientIP.parse_cidr("10.0.0.0/33") == :error
+    assert ClientIP.parse_cidr("not-an-ip") == :error
+  end
+end
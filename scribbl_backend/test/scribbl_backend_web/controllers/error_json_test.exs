@@ -2,11 +2,17 @@ defmodule ScribblBackendWeb.ErrorJSONTest do
   use ScribblBackendWeb.ConnCase, async: true
 
   test "renders 404" do
-    assert ScribblBackendWeb.ErrorJSON.render("404.json", %{}) == %{errors: %{detail: "Not Found"}}
+    assert ScribblBackendWeb.ErrorJSON.render("404.json", %{}) ==
+             %{errors: %{detail: "Not Found", code: "NOT_FOUND"}}
   end
 
   test "renders 500" do
     assert ScribblBackendWeb.ErrorJSON.render("500.json", %{}) ==
-             %{errors: %{detail: "Internal Server Error"}}
+             %{errors: %{detail: "Internal Server Error", code: "INTERNAL_ERROR"}}
+  end
+
+  test "falls back to a generic code for statuses outside the catalog" do
+    assert ScribblBackendWeb.ErrorJSON.render("418.json", %{}) ==
+             %{errors: %{detail: "I'm a teapot", code: "BAD_REQUEST"}}
   end
 end
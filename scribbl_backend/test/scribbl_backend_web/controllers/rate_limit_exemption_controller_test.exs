@@ -0,0 +1,52 @@
+defmodule ScribblBackendWeb.RateLimitExemptionControllerTest do
+  use ScribblBackendWeb.ConnCase, async: false
+
+  alias ScribblBackendWeb.RateLimitExemptions
+
+  @moduletag :redis
+
+  @entry "198.51.100.0/24"
+
+  setup %{conn: conn} do
+    on_exit(fn -> RateLimitExemptions.remove(@entry) end)
+    {:ok, conn: authorize_admin(conn)}
+  end
+
+  # Admin changes are applied by the GenServer after a broadcast
+  defp wait_for_reload, do: :sys.get_state(RateLimitExemptions)
+
+  test "adds, lists and removes an exemption", %{conn: conn} do
+    conn = post(conn, "/admin/rate-limit/exemptions", %{entry: " #{@entry} "})
+    assert json_response(conn, 201) == %{"entry" => @entry}
+
+    conn = get(recycle(conn), "/admin/rate-limit/exemptions")
+    assert @entry in json_response(conn, 200)["exemptions"]
+
+    conn = delete(recycle(conn), "/admin/rate-limit/exemptions", %{entry: @entry})
+    assert response(conn, 204)
+
+    conn = get(recycle(conn), "/admin/rate-limit/exemptions")
+    refute @entry in json_response(conn, 200)["exemptions"]
+  end
+
+  test "applies changes to exempt?/1 after the reload", %{conn: conn} do
+    refute RateLimitExemptions.exempt?({198, 51, 100, 7})
+
+    post(conn, "/admin/rate-limit/exemptions", %{entry: @entry})
+    wait_for_reload()
+    assert RateLimitExemptions.exempt?({198, 51, 100, 7})
+    refute RateLimitExemptions.exempt?({198, 51, 101, 7})
+
+    delete(recycle(conn), "/admin/rate-limit/exemptions", %{entry: @entry})
+    wait_for_reload()
+    refute RateLimitExemptions.exempt?({198, 51, 100, 7})
+  end
+
+  test "rejects invalid entries", %{conn: conn} do
+    conn = post(conn, "/admin/rate-limit/exemptions", %{entry: "not-an-ip"})
+    assert %{"code" => "BAD_REQUEST"} = json_response(conn, 400)
+
+    conn = delete(recycle(conn), "/admin/rate-limit/exemptions")
+    assert %{"code" => "BAD_REQUEST"} = json_response(conn, 400)
+  end
+end
@@ -0,0 +1,49 @@
+defmodule ScribblBackendWeb.DeprecationsTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackendWeb.Deprecations
+
+  @json ~s([
+    {"method": "get", "path": "/api/rooms/generate-id", "sunset": "2027-01-01",
+     "link": "https://example.com/v2"},
+    {"path": "/api/images/*", "deprecated_at": "2026-06-01T00:00:00Z", "message": "Use /v2/images"}
+  ])
+
+  test "matches exact paths by method and prefix paths for any method" do
+    entries = Deprecations.parse!(@json)
+
+    assert %{path: "/api/rooms/generate-id"} =
+             Deprecations.lookup("GET", "/api/rooms/generate-id", entries)
+
+    refute Deprecations.lookup("POST", "/api/rooms/generate-id", entries)
+    assert %{prefix?: true} = Deprecations.lookup("POST", "/api/images/game-over", entries)
+    refute Deprecations.lookup("GET", "/api/rooms/join-random", entries)
+  end
+
+  test "builds Deprecation, Sunset and Link headers" do
+    [rooms, images] = Deprecations.parse!(@json)
+
+    assert Deprecations.headers(rooms) == [
+             {"deprecation", "true"},
+             {"sunset", "Fri, 01 Jan 2027 00:00:00 GMT"},
+             {"link", ~s(<https://example.com/v2>; rel="deprecation")}
+           ]
+
+    assert Deprecations.headers(images) == [{"deprecation", "@1780272000"}]
+  end
+
+  test "builds warnings from the message or sunset date" do
+    [rooms, images] = Deprecations.parse!(@json)
+
+    assert Deprecations.warning(rooms) ==
+             "This endpoint is deprecated and will be removed on 2027-01-01"
+
+    assert Deprecations.warning(images) == "Use /v2/images"
+  end
+
+  test "rejects invalid config" do
+    assert_raise ArgumentError, fn -> Deprecations.parse!(~s({"path": "/api"})) end
+    assert_raise ArgumentError, fn -> Deprecations.parse!(~s([{"method": "GET"}])) end
+    assert_raise ArgumentError, fn -> Deprecations.parse!(~s([{"path": "/api", "sunset": "soon"}])) end
+  end
+end
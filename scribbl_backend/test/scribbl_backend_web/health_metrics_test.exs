@@ -0,0 +1,26 @@
+defmodule ScribblBackendWeb.HealthMetricsTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackendWeb.HealthMetrics
+
+  test "renders the current status as a stateset" do
+    health = %{redis: %{status: :degraded, since: ~U[2025-01-01 00:00:00.500Z]}}
+    text = HealthMetrics.render(health, %{})
+
+    assert text =~ ~s(scribbl_health_status{dependency="redis",scribbl_health_status="healthy"} 0\n)
+    assert text =~ ~s(scribbl_health_status{dependency="redis",scribbl_health_status="degraded"} 1\n)
+    assert text =~ ~s(scribbl_health_status_since_seconds{dependency="redis"} 1735689600.500\n)
+  end
+
+  test "renders transition counters" do
+    counts = %{{:redis, :unknown, :healthy} => 1, {:redis, :healthy, :unhealthy} => 3}
+    text = HealthMetrics.render(%{}, counts)
+
+    assert text =~ ~s(scribbl_health_transitions_total{dependency="redis",from="healthy",to="unhealthy"} 3\n)
+    assert text =~ ~s(scribbl_health_transitions_total{dependency="redis",from="unknown",to="healthy"} 1\n)
+  end
+
+  test "ends with the EOF marker" do
+    assert HealthMetrics.render(%{}, %{}) |> String.ends_with?("# EOF\n")
+  end
+end
@@ -0,0 +1,38 @@
+defmodule ScribblBackendWeb.InternalEndpointTest do
+  # Not async: toggles the node-wide :internal_listener setting
+  use ScribblBackendWeb.ConnCase, async: false
+
+  setup %{conn: conn} do
+    Application.put_env(:scribbl_backend, :internal_listener, true)
+    on_exit(fn -> Application.delete_env(:scribbl_backend, :internal_listener) end)
+
+    {:ok, conn: authorize_admin(conn)}
+  end
+
+  test "serves the admin API on the internal endpoint", %{conn: conn} do
+    conn = dispatch(conn, ScribblBackendWeb.InternalEndpoint, :get, "/admin/loglevel")
+
+    assert %{"level" => _level} = json_response(conn, 200)
+  end
+
+  test "hides the admin API on the public endpoint", %{conn: conn} do
+    for {method, path} <- [get: "/admin/loglevel", put: "/admin/read-only", get: "/admin/metrics"] do
+      conn = dispatch(recycle(conn), ScribblBackendWeb.Endpoint, method, path)
+      assert %{"code" => "NOT_FOUND"} = json_response(conn, 404)
+    end
+  end
+
+  test "serves the admin API on the public endpoint without an internal listener", %{conn: conn} do
+    Application.delete_env(:scribbl_backend, :internal_listener)
+
+    conn = get(conn, "/admin/loglevel")
+
+    assert %{"level" => _level} = json_response(conn, 200)
+  end
+
+  test "does not serve public routes on the internal endpoint", %{conn: conn} do
+    assert_error_sent 404, fn ->
+      dispatch(conn, ScribblBackendWeb.InternalEndpoint, :get, "/status")
+    end
+  end
+end
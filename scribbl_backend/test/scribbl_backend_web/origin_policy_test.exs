@@ -0,0 +1,40 @@
+defmodule ScribblBackendWeb.OriginPolicyTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackendWeb.OriginPolicy
+
+  test "builds a policy with defaults for unset values" do
+    policy = OriginPolicy.new!(allowed_origins: ["https://scribbl.club"], cookie_domain: "", cookie_same_site: nil)
+
+    assert policy.cookie_same_site == "Lax"
+    assert OriginPolicy.cookie_options(policy) == [same_site: "Lax", secure: false]
+  end
+
+  test "rejects origins that CORS would never match" do
+    assert_raise ArgumentError, fn -> OriginPolicy.new!(allowed_origins: ["https://scribbl.club/"]) end
+    assert_raise ArgumentError, fn -> OriginPolicy.new!(allowed_origins: ["scribbl.club"]) end
+  end
+
+  test "allows any origin unless the cookie is cross-site" do
+    assert %OriginPolicy{allowed_origins: ["*"]} = OriginPolicy.new!(allowed_origins: ["*"])
+
+    assert_raise ArgumentError, fn ->
+      OriginPolicy.new!(allowed_origins: ["*"], cookie_same_site: "None", cookie_secure: true)
+    end
+  end
+
+  test "requires a secure cookie for SameSite=None" do
+    assert_raise ArgumentError, fn -> OriginPolicy.new!(cookie_same_site: "None") end
+    assert %OriginPolicy{} = OriginPolicy.new!(cookie_same_site: "None", cookie_secure: true)
+    assert_raise ArgumentError, fn -> OriginPolicy.new!(cookie_same_site: "lax") end
+  end
+
+  test "requires the cookie domain to cover the API host" do
+    policy = OriginPolicy.new!(cookie_domain: ".scribbl.club", api_host: "api.scribbl.club")
+    assert [domain: ".scribbl.club"] = OriginPolicy.cookie_options(policy) |> Keyword.take([:domain])
+
+    assert_raise ArgumentError, fn ->
+      OriginPolicy.new!(cookie_domain: "scribbl.club", api_host: "api.example.com")
+    end
+  end
+end
@@ -0,0 +1,101 @@
+defmodule ScribblBackendWeb.Plugs.ConcurrencyLimitTest do
+  use ExUnit.Case, async: false
+
+  import Plug.Test
+
+  alias ScribblBackendWeb.ConcurrencyLimiter
+  alias ScribblBackendWeb.Plugs.ConcurrencyLimit
+
+  defmodule OkPlug do
+    def init(opts), do: opts
+
+    def call(conn, _opts) do
+      send(self(), {:in_flight, ConcurrencyLimiter.in_flight(conn.remote_ip)})
+      Plug.Conn.send_resp(conn, 200, "ok")
+    end
+  end
+
+  defmodule RaisingPlug do
+    def init(opts), do: opts
+    def call(_conn, _opts), do: raise("boom")
+  end
+
+  setup do
+    Application.put_env(:scribbl_backend, :max_in_flight_per_ip, 2)
+    on_exit(fn -> Application.delete_env(:scribbl_backend, :max_in_flight_per_ip) end)
+  end
+
+  defp conn_from(ip), do: %{conn(:get, "/api/rooms/generate-id") | remote_ip: ip}
+
+  test "acquire is refused at the limit until a slot is released" do
+    ip = {203, 0, 113, 1}
+
+    assert {:ok, ref} = ConcurrencyLimiter.acquire(ip, 1)
+    assert {:error, :limit_reached} = ConcurrencyLimiter.acquire(ip, 1)
+    assert ConcurrencyLimiter.in_flight(ip) == 1
+
+    ConcurrencyLimiter.release(ip, ref)
+    assert ConcurrencyLimiter.in_flight(ip) == 0
+    assert {:ok, ref} = ConcurrencyLimiter.acquire(ip, 1)
+    ConcurrencyLimiter.release(ip, ref)
+  end
+
+  test "the sweep keeps slots of live requests however long they run" do
+    ip = {203, 0, 113, 5}
+    {:ok, ref} = ConcurrencyLimiter.acquire(ip, 1)
+
+    ConcurrencyLimiter.sweep()
+
+    assert ConcurrencyLimiter.in_flight(ip) == 1
+    assert {:error, :limit_reached} = ConcurrencyLimiter.acquire(ip, 1)
+    ConcurrencyLimiter.release(ip, ref)
+  end
+
+  test "the sweep evicts slots whose request process died" do
+    ip = {203, 0, 113, 6}
+
+    parent = self()
+    {pid, monitor} = spawn_monitor(fn -> send(parent, ConcurrencyLimiter.acquire(ip, 1)) end)
+    assert_receive {:ok, _ref}
+    assert_receive {:DOWN, ^monitor, :process, ^pid, :normal}
+    assert ConcurrencyLimiter.in_flight(ip) == 1
+
+    ConcurrencyLimiter.sweep()
+
+    assert ConcurrencyLimiter.in_flight(ip) == 0
+  end
+
+  test "holds a slot while the request runs and releases it afterwards" do
+    ip = {203, 0, 113, 2}
+
+    conn = ConcurrencyLimit.call(conn_from(ip), ConcurrencyLimit.init(plug: OkPlug))
+
+    assert conn.status == 200
+    assert_received {:in_flight, 1}
+    assert ConcurrencyLimiter.in_flight(ip) == 0
+  end
+
+  test "releases the slot when a later plug raises" do
+    ip = {203, 0, 113, 3}
+    opts = ConcurrencyLimit.init(plug: RaisingPlug)
+
+    for _ <- 1..3 do
+      assert_raise RuntimeError, fn -> ConcurrencyLimit.call(conn_from(ip), opts) end
+    end
+
+    assert ConcurrencyLimiter.in_flight(ip) == 0
+  end
+
+  test "rejects with 429 when the client is at the limit" do
+    ip = {203, 0, 113, 4}
+    refs = for _ <- 1..2, do: elem(ConcurrencyLimiter.acquire(ip, 2), 1)
+
+    conn = ConcurrencyLimit.call(conn_from(ip), ConcurrencyLimit.init(plug: OkPlug))
+
+    assert conn.status == 429
+    assert conn.halted
+    refute_received {:in_flight, _}
+
+    Enum.each(refs, &ConcurrencyLimiter.release(ip, &1))
+  end
+end
@@ -0,0 +1,37 @@
+defmodule ScribblBackendWeb.Plugs.DeprecationTest do
+  # Not async: deprecations are read from the application env
+  use ScribblBackendWeb.ConnCase, async: false
+
+  alias ScribblBackendWeb.Deprecations
+
+  setup do
+    on_exit(fn -> Application.delete_env(:scribbl_backend, :deprecations) end)
+  end
+
+  defp deprecate(json) do
+    Application.put_env(:scribbl_backend, :deprecations, Deprecations.parse!(json))
+  end
+
+  # /status goes through the :api pipeline without touching Redis
+  test "announces a deprecated route", %{conn: conn} do
+    deprecate(~s([{"method": "GET", "path": "/status", "sunset": "2027-01-01",
+                   "link": "https://scribbl.club/docs/api-v2"}]))
+
+    conn = get(conn, "/status")
+
+    assert get_resp_header(conn, "deprecation") == ["true"]
+    assert get_resp_header(conn, "sunset") == ["Fri, 01 Jan 2027 00:00:00 GMT"]
+    assert get_resp_header(conn, "link") == [~s(<https://scribbl.club/docs/api-v2>; rel="deprecation")]
+    assert json_response(conn, 200)["warning"] =~ "2027-01-01"
+  end
+
+  test "leaves other routes alone", %{conn: conn} do
+    deprecate(~s([{"path": "/api/rooms/*", "sunset": "2027-01-01"}]))
+
+    conn = get(conn, "/status")
+
+    assert get_resp_header(conn, "deprecation") == []
+    assert get_resp_header(conn, "sunset") == []
+    refute Map.has_key?(json_response(conn, 200), "warning")
+  end
+end
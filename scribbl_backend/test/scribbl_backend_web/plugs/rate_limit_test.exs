@@ -0,0 +1,57 @@
+defmodule ScribblBackendWeb.Plugs.RateLimitTest do
+  use ScribblBackendWeb.ConnCase, async: true
+
+  alias ScribblBackendWeb.Plugs.RateLimit
+
+  # Reports the limits it was asked to count, and answers with the infos the
+  # test put in the process dictionary
+  defmodule StubLimiter do
+    def hit(limits) do
+      send(self(), {:limits, limits})
+      Process.get(:rate_limiter_result)
+    end
+  end
+
+  defp call(conn, result) do
+    Process.put(:rate_limiter_result, result)
+    RateLimit.call(%{conn | remote_ip: {203, 0, 113, 10}}, RateLimit.init(limiter: StubLimiter))
+  end
+
+  test "counts requests against every matching policy" do
+    infos = [%{limit: 10, remaining: 9, reset_seconds: 60}, %{limit: 300, remaining: 299, reset_seconds: 60}]
+    call(build_conn(:post, "/api/images/game-over"), {:ok, infos})
+
+    assert_received {:limits, [per_ip, global]}
+    assert %{bucket: "game_over_image", client: "203.0.113.10", limit: 10} = per_ip
+    assert %{bucket: "game_over_image_global", client: "all", limit: 300} = global
+  end
+
+  test "reports the policy with the fewest remaining requests" do
+    infos = [%{limit: 10, remaining: 7, reset_seconds: 40}, %{limit: 300, remaining: 2, reset_seconds: 15}]
+    conn = call(build_conn(:post, "/api/images/game-over"), {:ok, infos})
+
+    refute conn.halted
+    assert get_resp_header(conn, "x-ratelimit-limit") == ["300"]
+    assert get_resp_header(conn, "x-ratelimit-remaining") == ["2"]
+    assert get_resp_header(conn, "x-ratelimit-reset") == ["15"]
+  end
+
+  test "rejects with the limiter's retry time when a policy is exceeded" do
+    infos = [%{limit: 10, remaining: 0, reset_seconds: 40}, %{limit: 300, remaining: 120, reset_seconds: 15}]
+    conn = call(build_conn(:post, "/api/images/game-over"), {:error, :limit_reached, infos, 25})
+
+    assert conn.halted
+    assert %{"code" => "RATE_LIMITED", "retry_after_seconds" => 25} = json_response(conn, 429)
+    assert get_resp_header(conn, "retry-after") == ["25"]
+    assert get_resp_header(conn, "x-ratelimit-limit") == ["10"]
+  end
+
+  @tag capture_log: true
+  test "allows the request when Redis is unavailable" do
+    conn = call(build_conn(:get, "/api/rooms/generate-id"), {:error, %Redix.ConnectionError{reason: :closed}})
+
+    refute conn.halted
+    assert conn.status == nil
+    assert get_resp_header(conn, "x-ratelimit-limit") == []
+  end
+end
@@ -0,0 +1,46 @@
+defmodule ScribblBackendWeb.Plugs.ReadOnlyTest do
+  # Not async: read-only mode is node-wide
+  use ScribblBackendWeb.ConnCase, async: false
+
+  alias ScribblBackend.ReadOnlyMode
+  alias ScribblBackendWeb.Plugs.ReadOnly
+
+  setup do
+    set_read_only(true)
+    on_exit(fn -> set_read_only(false) end)
+  end
+
+  defp set_read_only(enabled) do
+    ReadOnlyMode.set(enabled)
+    # The toggle is applied by the GenServer, wait until it has been handled
+    :sys.get_state(ReadOnlyMode)
+  end
+
+  test "rejects mutating requests with 503 READ_ONLY" do
+    conn = ReadOnly.call(build_conn(:post, "/api/rooms"), ReadOnly.init([]))
+
+    assert conn.halted
+    assert %{"code" => "READ_ONLY"} = json_response(conn, 503)
+  end
+
+  test "lets reads through" do
+    conn = ReadOnly.call(build_conn(:get, "/api/rooms/generate-id"), ReadOnly.init([]))
+
+    refute conn.halted
+    assert conn.status == nil
+  end
+
+  test "lets game-over image rendering through" do
+    conn = ReadOnly.call(build_conn(:post, "/api/images/game-over"), ReadOnly.init([]))
+
+    refute conn.halted
+  end
+
+  test "lets mutating requests through when disabled" do
+    set_read_only(false)
+
+    conn = ReadOnly.call(build_conn(:post, "/api/rooms"), ReadOnly.init([]))
+
+    refute conn.halted
+  end
+end
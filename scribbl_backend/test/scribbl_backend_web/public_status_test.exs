@@ -0,0 +1,25 @@
+defmodule ScribblBackendWeb.PublicStatusTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackendWeb.PublicStatus
+
+  test "reports operational when dependencies are healthy" do
+    assert PublicStatus.build(%{redis: %{status: :healthy}}, false) == %{
+             status: "operational",
+             components: %{api: "operational", game_rooms: "operational"}
+           }
+  end
+
+  test "maps dependency health onto components" do
+    assert %{status: "degraded"} = PublicStatus.build(%{redis: %{status: :degraded}}, false)
+    assert %{status: "outage", components: %{api: "outage"}} =
+             PublicStatus.build(%{redis: %{status: :unhealthy}}, false)
+  end
+
+  test "reports game rooms under maintenance in read-only mode" do
+    assert PublicStatus.build(%{redis: %{status: :healthy}}, true) == %{
+             status: "maintenance",
+             components: %{api: "operational", game_rooms: "maintenance"}
+           }
+  end
+end
@@ -0,0 +1,37 @@
+defmodule ScribblBackendWeb.RateLimitPoliciesTest do
+  use ExUnit.Case, async: false
+
+  alias ScribblBackendWeb.RateLimitPolicies
+
+  setup do
+    on_exit(fn -> Application.delete_env(:scribbl_backend, :rate_limit_policies) end)
+  end
+
+  test "returns every policy registered for an endpoint" do
+    names =
+      "POST"
+      |> RateLimitPolicies.for_request("/api/images/game-over")
+      |> Enum.map(& &1.name)
+
+    assert names == ["game_over_image", "game_over_image_global"]
+  end
+
+  test "falls back to the api policy" do
+    assert [%{name: "api", key: :ip}] = RateLimitPolicies.for_request("GET", "/api/unknown")
+    assert [%{name: "api"}] = RateLimitPolicies.for_request("POST", "/api/rooms/join-random")
+  end
+
+  test "applies configured overrides" do
+    overrides = RateLimitPolicies.parse_overrides!(~s({"join_random": {"limit": 5}, "api": {"window_seconds": 10}}))
+    Application.put_env(:scribbl_backend, :rate_limit_policies, overrides)
+
+    assert [%{limit: 5, window_seconds: 60}] = RateLimitPolicies.for_request("GET", "/api/rooms/join-random")
+    assert [%{name: "api", window_seconds: 10}] = RateLimitPolicies.for_request("GET", "/api/unknown")
+  end
+
+  test "rejects invalid overrides" do
+    assert_raise ArgumentError, fn -> RateLimitPolicies.parse_overrides!(~s({"nope": {"limit": 5}})) end
+    assert_raise ArgumentError, fn -> RateLimitPolicies.parse_overrides!(~s({"api": {"limit": 0}})) end
+    assert_raise ArgumentError, fn -> RateLimitPolicies.parse_overrides!(~s({"api": {"key": "global"}})) end
+  end
+end
@@ -0,0 +1,24 @@
+defmodule ScribblBackendWeb.RateLimitedTest do
+  use ScribblBackendWeb.ConnCase, async: true
+
+  alias ScribblBackendWeb.RateLimited
+
+  test "sets Retry-After and retry_after_seconds", %{conn: conn} do
+    conn = RateLimited.reject(conn, "Too many requests", 42)
+
+    assert conn.halted
+    assert get_resp_header(conn, "retry-after") == ["42"]
+
+    assert json_response(conn, 429) == %{
+             "error" => "Too many requests",
+             "code" => "RATE_LIMITED",
+             "retry_after_seconds" => 42
+           }
+  end
+
+  test "never tells clients to retry immediately", %{conn: conn} do
+    conn = RateLimited.reject(conn, "Too many requests", 0)
+
+    assert get_resp_header(conn, "retry-after") == ["1"]
+  end
+end
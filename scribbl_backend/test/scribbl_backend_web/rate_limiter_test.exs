@@ -0,0 +1,86 @@
+defmodule ScribblBackendWeb.RateLimiterTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackend.KeyManager
+  alias ScribblBackend.RedisHelper
+  alias ScribblBackendWeb.RateLimiter
+
+  @moduletag :redis
+
+  setup do
+    client = "test-#{System.unique_integer([:positive])}"
+
+    on_exit(fn ->
+      Redix.command(RedisHelper.redix_conn(), [
+        "DEL",
+        KeyManager.rate_limit("short", client),
+        KeyManager.rate_limit("long", client)
+      ])
+    end)
+
+    {:ok, client: client}
+  end
+
+  defp limit(bucket, client, limit, window_seconds) do
+    %{bucket: bucket, client: client, limit: limit, window_seconds: window_seconds}
+  end
+
+  defp zcard(bucket, client) do
+    {:ok, count} = Redix.command(RedisHelper.redix_conn(), ["ZCARD", KeyManager.rate_limit(bucket, client)])
+    count
+  end
+
+  test "allows requests up to the limit and reports the remaining quota", %{client: client} do
+    limits = [limit("short", client, 2, 10)]
+
+    assert {:ok, [%{limit: 2, remaining: 1, reset_seconds: 10}]} = RateLimiter.hit(limits)
+    assert {:ok, [%{remaining: 0}]} = RateLimiter.hit(limits)
+    assert {:error, :limit_reached, [%{remaining: 0}], 10} = RateLimiter.hit(limits)
+  end
+
+  test "rejects when one of two limits is exceeded, with that limit's retry time", %{client: client} do
+    limits = [limit("long", client, 5, 60), limit("short", client, 1, 10)]
+
+    assert {:ok, _infos} = RateLimiter.hit(limits)
+
+    assert {:error, :limit_reached, [long, short], 10} = RateLimiter.hit(limits)
+    assert %{limit: 5, remaining: 4} = long
+    assert %{limit: 1, remaining: 0} = short
+  end
+
+  test "a rejected request uses up no quota in any limit", %{client: client} do
+    limits = [limit("long", client, 5, 60), limit("short", client, 1, 10)]
+
+    assert {:ok, _infos} = RateLimiter.hit(limits)
+
+    for _ <- 1..3 do
+      assert {:error, :limit_reached, _infos, _retry_after} = RateLimiter.hit(limits)
+    end
+
+    assert zcard("long", client) == 1
+    assert zcard("short", client) == 1
+  end
+
+  test "waits for the longest of several exceeded limits", %{client: client} do
+    limits = [limit("long", client, 1, 60), limit("short", client, 1, 10)]
+
+    assert {:ok, _infos} = RateLimiter.hit(limits)
+    assert {:error, :limit_reached, _infos, 60} = RateLimiter.hit(limits)
+  end
+
+  test "waits until enough requests leave the window when over the limit", %{client: client} do
+    # More requests counted than the limit allows, e.g. after the limit was lowered
+    key = KeyManager.rate_limit("long", client)
+    now = System.system_time(:millisecond)
+
+    for {age_seconds, member} <- [{50, "a"}, {30, "b"}, {10, "c"}] do
+      Redix.command(RedisHelper.redix_conn(), ["ZADD", key, to_string(now - age_seconds * 1000), member])
+    end
+
+    # With a limit of 2 the two oldest requests must expire, the second one in 30s
+    assert {:error, :limit_reached, [%{remaining: 0, reset_seconds: 10}], 30} =
+             RateLimiter.hit([limit("long", client, 2, 60)])
+
+    assert zcard("long", client) == 3
+  end
+end
@@ -0,0 +1,23 @@
+defmodule ScribblBackendWeb.RejectionMetricsTest do
+  use ExUnit.Case, async: true
+
+  alias ScribblBackendWeb.RejectionMetrics
+
+  test "renders counts as an OpenMetrics counter family" do
+    counts = %{{"POST /api/images/game-over", :malformed_body} => 2, {"room:join", :invalid_name} => 1}
+    text = counts |> RejectionMetrics.families() |> IO.iodata_to_binary()
+
+    assert text =~ "# TYPE scribbl_request_rejected counter\n"
+    assert text =~ ~s(scribbl_request_rejected_total{endpoint="POST /api/images/game-over",reason="malformed_body"} 2\n)
+    assert text =~ ~s(scribbl_request_rejected_total{endpoint="room:join",reason="invalid_name"} 1\n)
+  end
+
+  test "adds rejections to the node totals" do
+    endpoint = "GET /test/#{System.unique_integer([:positive])}"
+
+    RejectionMetrics.rejected(endpoint, :invalid_field)
+    RejectionMetrics.rejected(endpoint, :invalid_field)
+
+    assert RejectionMetrics.counts()[{endpoint, :invalid_field}] == 2
+  end
+end
@@ -27,4 +27,16 @@ defmodule ScribblBackendWeb.ConnCase do
   setup _tags do
     {:ok, conn: Phoenix.ConnTest.build_conn()}
   end
+
+  @doc """
+  Configure an admin API token for the current test and authorize the
+  connection with it. Tests using it must not be async.
+  """
+  def authorize_admin(conn) do
+    token = "test-admin-token"
+    Application.put_env(:scribbl_backend, :admin_api_token, token)
+    ExUnit.Callbacks.on_exit(fn -> Application.delete_env(:scribbl_backend, :admin_api_token) end)
+
+    Plug.Conn.put_req_header(conn, "authorization", "Bearer " <> token)
+  end
 end
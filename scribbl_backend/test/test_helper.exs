@@ -1 +1,18 @@
-ExUnit.start()
+# Tests tagged :redis need a Redis server (REDIS_HOST/REDIS_PORT) and are
+# skipped when none is reachable
+redis_available? =
+  case :gen_tcp.connect(
+         String.to_charlist(System.get_env("REDIS_HOST") || "localhost"),
+         String.to_integer(System.get_env("REDIS_PORT") || "6379"),
+         [],
+         500
+       ) do
+    {:ok, socket} ->
+      :gen_tcp.close(socket)
+      true
+
+    {:error, _reason} ->
+      false
+  end
+
+ExUnit.start(exclude: if(redis_available?, do: [], else: [:redis]))